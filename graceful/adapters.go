@@ -0,0 +1,350 @@
+package graceful
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// HTTPServer is a [Runner] which serves HTTP requests via Server until
+// stopped.
+//
+// If Listener is nil, Server.Addr is listened on, otherwise Listener is served
+// as is, allowing a pre-bound listener to be used. The server serves HTTPS if
+// either CertFile & KeyFile are set or Server.TLSConfig provides certificates.
+// HTTPServer is ready once it is listening.
+//
+// An [http.Server] cannot be reused once it has been shut down so neither can
+// an HTTPServer.
+type HTTPServer struct {
+	Server   *http.Server
+	Listener net.Listener
+	CertFile string
+	KeyFile  string
+}
+
+// Start listening (if necessary) then serve requests until stopped.
+// [http.ErrServerClosed] is not treated as an error.
+func (s HTTPServer) Start(ctx context.Context) error {
+	ln := s.Listener
+	if ln == nil {
+		addr := s.Server.Addr
+		if addr == "" && s.tls() {
+			addr = ":https"
+		} else if addr == "" {
+			addr = ":http"
+		}
+
+		var err error
+		if ln, err = net.Listen("tcp", addr); err != nil {
+			return err
+		}
+	}
+	MarkReady(ctx)
+
+	errCh := make(chan error, 1)
+	go func() {
+		if s.tls() {
+			errCh <- s.Server.ServeTLS(ln, s.CertFile, s.KeyFile)
+		} else {
+			errCh <- s.Server.Serve(ln)
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	}
+}
+
+func (s HTTPServer) marksReady() {}
+
+// Stop gracefully shuts down the server, waiting for active requests to
+// complete. If the passed context is canceled first, the server is closed
+// forcefully.
+func (s HTTPServer) Stop(ctx context.Context) error {
+	if err := s.Server.Shutdown(ctx); err != nil {
+		_ = s.Server.Close()
+		return err
+	}
+
+	return nil
+}
+
+func (s HTTPServer) tls() bool {
+	if s.CertFile != "" || s.KeyFile != "" {
+		return true
+	}
+	cfg := s.Server.TLSConfig
+
+	return cfg != nil && (len(cfg.Certificates) != 0 || cfg.GetCertificate != nil || cfg.GetConfigForClient != nil)
+}
+
+// Listener is a [Runner] which accepts connections from Listener until
+// stopped, calling Handle for each of them in its own goroutine. Connections
+// are closed once Handle returns.
+//
+// Stopping closes Listener then waits for all calls to Handle to return. The
+// context passed to Handle is canceled if the context passed to
+// [Listener.Stop] is canceled first. Listener is ready once it is accepting
+// connections. A Listener must not be copied after first use and cannot be
+// restarted once stopped.
+type Listener struct {
+	Listener net.Listener
+	Handle   func(context.Context, net.Conn)
+
+	lifecycle
+}
+
+// Start accepting connections until stopped. Temporary accept errors are
+// retried with a backoff, any other accept error is returned.
+func (l *Listener) Start(ctx context.Context) error {
+	workCtx, stopCh, ok := l.begin(ctx)
+	if !ok {
+		return nil
+	}
+	defer l.end()
+
+	go func() {
+		select {
+		case <-stopCh:
+		case <-workCtx.Done():
+		}
+		_ = l.Listener.Close()
+	}()
+	MarkReady(ctx)
+
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+
+	var delay time.Duration
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			select {
+			case <-stopCh:
+				return nil
+			case <-workCtx.Done():
+				return ctx.Err()
+			default:
+			}
+
+			var netErr net.Error
+			if !errors.As(err, &netErr) || !netErr.Timeout() {
+				return err
+			}
+			delay = min(max(2*delay, 5*time.Millisecond), time.Second)
+			time.Sleep(delay)
+			continue
+		}
+		delay = 0
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Close()
+			l.Handle(workCtx, conn)
+		}()
+	}
+}
+
+func (l *Listener) marksReady() {}
+
+// Stop accepting connections then wait for all calls to Handle to return.
+func (l *Listener) Stop(ctx context.Context) error {
+	return l.stop(ctx)
+}
+
+// Ticker is a [Runner] which calls Task every Interval until stopped. If
+// Immediate is true, Task is also called as soon as the ticker starts.
+//
+// Stopping waits for an in-progress call to Task to return. The context passed
+// to Task is canceled if the context passed to [Ticker.Stop] is canceled
+// first. Ticker is ready once it has started. A Ticker must not be copied after
+// first use and cannot be restarted once stopped.
+type Ticker struct {
+	Interval  time.Duration
+	Task      func(context.Context) error
+	Immediate bool
+
+	lifecycle
+}
+
+// Start calling Task every Interval until stopped. If Task returns an error it
+// is no longer called and the error is returned.
+func (t *Ticker) Start(ctx context.Context) error {
+	if t.Interval <= 0 {
+		return fmt.Errorf("non-positive ticker interval %s", t.Interval)
+	}
+
+	workCtx, stopCh, ok := t.begin(ctx)
+	if !ok {
+		return nil
+	}
+	defer t.end()
+	MarkReady(ctx)
+
+	if t.Immediate {
+		if err := t.Task(workCtx); err != nil {
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(t.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return nil
+		case <-workCtx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := t.Task(workCtx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (t *Ticker) marksReady() {}
+
+// Stop calling Task then wait for an in-progress call to it to return.
+func (t *Ticker) Stop(ctx context.Context) error {
+	return t.stop(ctx)
+}
+
+// WorkerPool is a [Runner] which receives jobs from Jobs and calls Handle for
+// each of them from Workers goroutines until stopped or Jobs is closed. If
+// Workers is less than 1, a single goroutine is used.
+//
+// Stopping waits for in-progress calls to Handle to return. Jobs still
+// buffered in Jobs are left unreceived. The context passed to Handle is
+// canceled if the context passed to [WorkerPool.Stop] is canceled first.
+// WorkerPool is ready once it has started. A WorkerPool must not be copied
+// after first use and cannot be restarted once stopped.
+type WorkerPool[T any] struct {
+	Workers int
+	Jobs    <-chan T
+	Handle  func(context.Context, T) error
+
+	lifecycle
+}
+
+// Start handling jobs until stopped or Jobs is closed. If Handle returns an
+// error, all goroutines stop receiving jobs and the first such error is
+// returned.
+func (p *WorkerPool[T]) Start(ctx context.Context) error {
+	workCtx, stopCh, ok := p.begin(ctx)
+	if !ok {
+		return nil
+	}
+	defer p.end()
+	MarkReady(ctx)
+
+	eg, egCtx := errgroup.WithContext(workCtx)
+	for range max(p.Workers, 1) {
+		eg.Go(func() error {
+			for {
+				select {
+				case <-stopCh:
+					return nil
+				case <-egCtx.Done():
+					return ctx.Err()
+				case job, ok := <-p.Jobs:
+					if !ok {
+						return nil
+					}
+					if err := p.Handle(workCtx, job); err != nil {
+						return err
+					}
+				}
+			}
+		})
+	}
+
+	return eg.Wait()
+}
+
+func (p *WorkerPool[T]) marksReady() {}
+
+// Stop receiving jobs then wait for in-progress calls to Handle to return.
+func (p *WorkerPool[T]) Stop(ctx context.Context) error {
+	return p.stop(ctx)
+}
+
+// lifecycle tracks whether a [Runner] has been stopped and whether its
+// [Runner.Start] is still in progress.
+type lifecycle struct {
+	mu       sync.Mutex
+	stopping bool
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+	cancel   context.CancelFunc
+}
+
+// begin returns a context for the work of [Runner.Start] derived from ctx and
+// a channel which is closed once stopping begins. It returns false if the
+// runner has already been stopped.
+func (l *lifecycle) begin(ctx context.Context) (context.Context, <-chan struct{}, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.stopping {
+		return nil, nil, false
+	}
+	if l.stopCh == nil {
+		l.stopCh = make(chan struct{})
+	}
+	ctx, l.cancel = context.WithCancel(ctx)
+	l.doneCh = make(chan struct{})
+
+	return ctx, l.stopCh, true
+}
+
+// end marks [Runner.Start] as having returned.
+func (l *lifecycle) end() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.cancel()
+	close(l.doneCh)
+}
+
+// stop prevents any further starts then waits for an in-progress
+// [Runner.Start] to return. If ctx is canceled first, the context for the work
+// of [Runner.Start] is canceled.
+func (l *lifecycle) stop(ctx context.Context) error {
+	l.mu.Lock()
+	if !l.stopping {
+		l.stopping = true
+		if l.stopCh != nil {
+			close(l.stopCh)
+		}
+	}
+	doneCh, cancel := l.doneCh, l.cancel
+	l.mu.Unlock()
+
+	if doneCh == nil {
+		return nil
+	}
+
+	select {
+	case <-doneCh:
+		return nil
+	case <-ctx.Done():
+		cancel()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,319 @@
+package graceful_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wafer-bw/go-toolbox/graceful"
+)
+
+func TestHTTPServer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("serves requests on a pre-bound listener until stopped", func(t *testing.T) {
+		t.Parallel()
+
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		s := graceful.HTTPServer{
+			Server: &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			})},
+			Listener: ln,
+		}
+
+		errCh := make(chan error, 1)
+		go func() { errCh <- s.Start(t.Context()) }()
+
+		resp, err := http.Get("http://" + ln.Addr().String())
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		require.Equal(t, http.StatusTeapot, resp.StatusCode)
+
+		require.NoError(t, s.Stop(t.Context()))
+		require.NoError(t, <-errCh)
+	})
+
+	t.Run("serves TLS using the server TLS config", func(t *testing.T) {
+		t.Parallel()
+
+		ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		}))
+		ts.StartTLS()
+		client := ts.Client()
+		tlsConfig := ts.TLS
+		ts.Close()
+
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		s := graceful.HTTPServer{
+			Server: &http.Server{
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusTeapot)
+				}),
+				TLSConfig: tlsConfig,
+			},
+			Listener: ln,
+		}
+
+		errCh := make(chan error, 1)
+		go func() { errCh <- s.Start(t.Context()) }()
+
+		resp, err := client.Get("https://" + ln.Addr().String())
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		require.Equal(t, http.StatusTeapot, resp.StatusCode)
+
+		require.NoError(t, s.Stop(t.Context()))
+		require.NoError(t, <-errCh)
+	})
+
+	t.Run("returns immediately when stopped before starting", func(t *testing.T) {
+		t.Parallel()
+
+		s := graceful.HTTPServer{Server: &http.Server{Addr: "127.0.0.1:0"}}
+		require.NoError(t, s.Stop(t.Context()))
+		require.NoError(t, s.Start(t.Context()))
+	})
+
+	t.Run("returns listen error", func(t *testing.T) {
+		t.Parallel()
+
+		s := graceful.HTTPServer{Server: &http.Server{Addr: "127.0.0.1:-1"}}
+		require.Error(t, s.Start(t.Context()))
+	})
+}
+
+func TestListener(t *testing.T) {
+	t.Parallel()
+
+	t.Run("handles connections until stopped", func(t *testing.T) {
+		t.Parallel()
+
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		l := &graceful.Listener{
+			Listener: ln,
+			Handle: func(ctx context.Context, conn net.Conn) {
+				_, _ = io.Copy(conn, conn)
+			},
+		}
+
+		errCh := make(chan error, 1)
+		go func() { errCh <- l.Start(t.Context()) }()
+
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		require.NoError(t, err)
+		_, err = conn.Write([]byte("ping"))
+		require.NoError(t, err)
+		buf := make([]byte, 4)
+		_, err = io.ReadFull(conn, buf)
+		require.NoError(t, err)
+		require.Equal(t, "ping", string(buf))
+		require.NoError(t, conn.Close())
+
+		require.NoError(t, l.Stop(t.Context()))
+		require.NoError(t, <-errCh)
+	})
+
+	t.Run("cancels handlers when the stop context is canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		handlingCh := make(chan struct{})
+		l := &graceful.Listener{
+			Listener: ln,
+			Handle: func(ctx context.Context, conn net.Conn) {
+				close(handlingCh)
+				<-ctx.Done()
+			},
+		}
+
+		errCh := make(chan error, 1)
+		go func() { errCh <- l.Start(t.Context()) }()
+
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+		<-handlingCh
+
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, l.Stop(ctx), context.DeadlineExceeded)
+		require.NoError(t, <-errCh)
+	})
+
+	t.Run("returns immediately when stopped before starting", func(t *testing.T) {
+		t.Parallel()
+
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer ln.Close()
+
+		l := &graceful.Listener{Listener: ln}
+		require.NoError(t, l.Stop(t.Context()))
+		require.NoError(t, l.Start(t.Context()))
+	})
+}
+
+func TestTicker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("calls task every interval until stopped", func(t *testing.T) {
+		t.Parallel()
+
+		calls := &atomic.Int32{}
+		tick := &graceful.Ticker{
+			Interval: time.Millisecond,
+			Task: func(ctx context.Context) error {
+				calls.Add(1)
+				return nil
+			},
+		}
+
+		errCh := make(chan error, 1)
+		go func() { errCh <- tick.Start(t.Context()) }()
+
+		require.Eventually(t, func() bool { return calls.Load() >= 3 }, time.Second, time.Millisecond)
+		require.NoError(t, tick.Stop(t.Context()))
+		require.NoError(t, <-errCh)
+	})
+
+	t.Run("calls task immediately when configured to", func(t *testing.T) {
+		t.Parallel()
+
+		calledCh := make(chan struct{})
+		tick := &graceful.Ticker{
+			Interval:  time.Hour,
+			Immediate: true,
+			Task: func(ctx context.Context) error {
+				close(calledCh)
+				return nil
+			},
+		}
+
+		errCh := make(chan error, 1)
+		go func() { errCh <- tick.Start(t.Context()) }()
+
+		<-calledCh
+		require.NoError(t, tick.Stop(t.Context()))
+		require.NoError(t, <-errCh)
+	})
+
+	t.Run("returns task error", func(t *testing.T) {
+		t.Parallel()
+
+		taskErr := errors.New("task failed")
+		tick := &graceful.Ticker{
+			Interval: time.Millisecond,
+			Task:     func(ctx context.Context) error { return taskErr },
+		}
+		require.ErrorIs(t, tick.Start(t.Context()), taskErr)
+	})
+
+	t.Run("returns an error when interval is not positive", func(t *testing.T) {
+		t.Parallel()
+
+		tick := &graceful.Ticker{}
+		require.Error(t, tick.Start(t.Context()))
+	})
+
+	t.Run("returns context error when the start context is canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		tick := &graceful.Ticker{Interval: time.Hour}
+		require.ErrorIs(t, tick.Start(ctx), context.Canceled)
+	})
+}
+
+func TestWorkerPool(t *testing.T) {
+	t.Parallel()
+
+	t.Run("handles jobs until jobs is closed", func(t *testing.T) {
+		t.Parallel()
+
+		jobs := make(chan int, 10)
+		for i := range 10 {
+			jobs <- i
+		}
+		close(jobs)
+
+		sum := &atomic.Int64{}
+		p := &graceful.WorkerPool[int]{
+			Workers: 3,
+			Jobs:    jobs,
+			Handle: func(ctx context.Context, job int) error {
+				sum.Add(int64(job))
+				return nil
+			},
+		}
+		require.NoError(t, p.Start(t.Context()))
+		require.Equal(t, int64(45), sum.Load())
+	})
+
+	t.Run("waits for in-progress jobs when stopped", func(t *testing.T) {
+		t.Parallel()
+
+		jobs := make(chan int, 1)
+		jobs <- 1
+		handlingCh := make(chan struct{})
+		releaseCh := make(chan struct{})
+		done := &atomic.Bool{}
+		p := &graceful.WorkerPool[int]{
+			Jobs: jobs,
+			Handle: func(ctx context.Context, job int) error {
+				close(handlingCh)
+				<-releaseCh
+				done.Store(true)
+				return nil
+			},
+		}
+
+		errCh := make(chan error, 1)
+		go func() { errCh <- p.Start(t.Context()) }()
+		<-handlingCh
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			close(releaseCh)
+		}()
+		require.NoError(t, p.Stop(t.Context()))
+		require.True(t, done.Load())
+		require.NoError(t, <-errCh)
+	})
+
+	t.Run("returns handle error", func(t *testing.T) {
+		t.Parallel()
+
+		jobs := make(chan int, 1)
+		jobs <- 1
+		handleErr := errors.New("handle failed")
+		p := &graceful.WorkerPool[int]{
+			Workers: 2,
+			Jobs:    jobs,
+			Handle:  func(ctx context.Context, job int) error { return handleErr },
+		}
+		require.ErrorIs(t, p.Start(t.Context()), handleErr)
+	})
+
+	t.Run("returns immediately when stopped before starting", func(t *testing.T) {
+		t.Parallel()
+
+		p := &graceful.WorkerPool[int]{Jobs: make(chan int)}
+		require.NoError(t, p.Stop(t.Context()))
+		require.NoError(t, p.Start(t.Context()))
+	})
+}
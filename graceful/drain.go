@@ -0,0 +1,172 @@
+package graceful
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// Drain tracks in-flight work such as requests, messages, or jobs so that
+// stopping can let it finish while refusing any new work.
+//
+// Work is tracked by calling [Drain.Acquire] before starting it and
+// [Drain.Release] once it is finished. Drain satisfies [Runner]: stopping it
+// refuses any further [Drain.Acquire] then waits for all in-flight work to be
+// released. If the context passed to [Drain.Stop] is canceled first, the
+// contexts of all in-flight work are canceled.
+//
+// Drain is ready once it has started. The zero value is ready to use and a
+// Drain must not be copied after first use.
+type Drain struct {
+	mu       sync.Mutex
+	draining bool
+	inFlight int
+	drainCh  chan struct{}
+	idleCh   chan struct{}
+	abortCtx context.Context
+	abort    context.CancelFunc
+}
+
+type acquiredKey struct{ d *Drain }
+
+type acquisition struct {
+	once   sync.Once
+	cancel context.CancelFunc
+	stop   func() bool
+}
+
+// Acquire tracks a unit of in-flight work, returning a context derived from
+// ctx for it to use which must later be passed to [Drain.Release]. A
+// [DrainingError] is returned if the drain has been stopped.
+func (d *Drain) Acquire(ctx context.Context) (context.Context, error) {
+	d.mu.Lock()
+	d.init()
+	if d.draining {
+		d.mu.Unlock()
+		return nil, &DrainingError{}
+	}
+	d.inFlight++
+	abortCtx := d.abortCtx
+	d.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	acq := &acquisition{cancel: cancel, stop: context.AfterFunc(abortCtx, cancel)}
+
+	return context.WithValue(ctx, acquiredKey{d: d}, acq), nil
+}
+
+// Release the unit of in-flight work whose context, as returned by
+// [Drain.Acquire], is ctx. It is safe to call more than once and is a no-op
+// for any other context.
+func (d *Drain) Release(ctx context.Context) {
+	acq, ok := ctx.Value(acquiredKey{d: d}).(*acquisition)
+	if !ok {
+		return
+	}
+
+	acq.once.Do(func() {
+		acq.stop()
+		acq.cancel()
+
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.inFlight--
+		if d.draining && d.inFlight == 0 {
+			close(d.idleCh)
+		}
+	})
+}
+
+// InFlight returns the number of units of work which have been acquired but
+// not yet released.
+func (d *Drain) InFlight() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.inFlight
+}
+
+// Draining reports whether the drain has been stopped and is refusing new
+// work.
+func (d *Drain) Draining() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.draining
+}
+
+// Middleware wraps next so that each request it serves is tracked as in-flight
+// work. Requests received while draining are refused with a
+// [http.StatusServiceUnavailable] status and the connection is closed.
+func (d *Drain) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, err := d.Acquire(r.Context())
+		if err != nil {
+			w.Header().Set("Connection", "close")
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		defer d.Release(ctx)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Start blocks until the drain is stopped or the passed context is canceled.
+func (d *Drain) Start(ctx context.Context) error {
+	d.mu.Lock()
+	d.init()
+	drainCh := d.drainCh
+	d.mu.Unlock()
+	MarkReady(ctx)
+
+	select {
+	case <-drainCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *Drain) marksReady() {}
+
+// Stop refusing new work then wait for all in-flight work to be released.
+func (d *Drain) Stop(ctx context.Context) error {
+	d.mu.Lock()
+	d.init()
+	if !d.draining {
+		d.draining = true
+		close(d.drainCh)
+		if d.inFlight == 0 {
+			close(d.idleCh)
+		}
+	}
+	idleCh := d.idleCh
+	d.mu.Unlock()
+
+	select {
+	case <-idleCh:
+		return nil
+	case <-ctx.Done():
+		d.abort()
+		return ctx.Err()
+	}
+}
+
+// init must be called with mu held.
+func (d *Drain) init() {
+	if d.drainCh != nil {
+		return
+	}
+	d.drainCh = make(chan struct{})
+	d.idleCh = make(chan struct{})
+	d.abortCtx, d.abort = context.WithCancel(context.Background())
+}
+
+// DrainingError occurs when work is refused by a [Drain] which has been
+// stopped.
+type DrainingError struct{}
+
+func (e *DrainingError) Error() string {
+	return "draining"
+}
@@ -0,0 +1,145 @@
+package graceful_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wafer-bw/go-toolbox/graceful"
+)
+
+func TestDrain_Acquire(t *testing.T) {
+	t.Parallel()
+
+	t.Run("tracks in-flight work until released", func(t *testing.T) {
+		t.Parallel()
+
+		d := &graceful.Drain{}
+		ctx1, err := d.Acquire(t.Context())
+		require.NoError(t, err)
+		ctx2, err := d.Acquire(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, 2, d.InFlight())
+
+		d.Release(ctx1)
+		d.Release(ctx1)
+		require.Equal(t, 1, d.InFlight())
+		require.ErrorIs(t, ctx1.Err(), context.Canceled)
+
+		d.Release(ctx2)
+		require.Zero(t, d.InFlight())
+	})
+
+	t.Run("returns an error once draining", func(t *testing.T) {
+		t.Parallel()
+
+		d := &graceful.Drain{}
+		require.NoError(t, d.Stop(t.Context()))
+		require.True(t, d.Draining())
+
+		_, err := d.Acquire(t.Context())
+		require.IsType(t, &graceful.DrainingError{}, err)
+	})
+}
+
+func TestDrain_Release(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ignores contexts not returned by acquire", func(t *testing.T) {
+		t.Parallel()
+
+		d := &graceful.Drain{}
+		_, err := d.Acquire(t.Context())
+		require.NoError(t, err)
+
+		d.Release(t.Context())
+		require.Equal(t, 1, d.InFlight())
+	})
+}
+
+func TestDrain_Stop(t *testing.T) {
+	t.Parallel()
+
+	t.Run("waits for in-flight work to be released", func(t *testing.T) {
+		t.Parallel()
+
+		d := &graceful.Drain{}
+		ctx, err := d.Acquire(t.Context())
+		require.NoError(t, err)
+
+		released := make(chan struct{})
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			d.Release(ctx)
+			close(released)
+		}()
+
+		require.NoError(t, d.Stop(t.Context()))
+		<-released
+		require.Zero(t, d.InFlight())
+	})
+
+	t.Run("cancels in-flight work when the stop context is canceled", func(t *testing.T) {
+		t.Parallel()
+
+		d := &graceful.Drain{}
+		workCtx, err := d.Acquire(t.Context())
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, d.Stop(ctx), context.DeadlineExceeded)
+		<-workCtx.Done()
+		require.Equal(t, 1, d.InFlight())
+	})
+
+	t.Run("makes start return", func(t *testing.T) {
+		t.Parallel()
+
+		d := &graceful.Drain{}
+		errCh := make(chan error, 1)
+		go func() { errCh <- d.Start(t.Context()) }()
+
+		require.NoError(t, d.Stop(t.Context()))
+		require.NoError(t, <-errCh)
+	})
+}
+
+func TestDrain_Middleware(t *testing.T) {
+	t.Parallel()
+
+	t.Run("tracks requests as in-flight work", func(t *testing.T) {
+		t.Parallel()
+
+		d := &graceful.Drain{}
+		var inFlight int
+		h := d.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			inFlight = d.InFlight()
+			w.WriteHeader(http.StatusNoContent)
+		}))
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.Equal(t, 1, inFlight)
+		require.Zero(t, d.InFlight())
+	})
+
+	t.Run("refuses requests once draining", func(t *testing.T) {
+		t.Parallel()
+
+		d := &graceful.Drain{}
+		require.NoError(t, d.Stop(t.Context()))
+		h := d.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("handler called while draining")
+		}))
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		require.Equal(t, "close", rec.Header().Get("Connection"))
+	})
+}
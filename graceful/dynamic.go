@@ -0,0 +1,245 @@
+package graceful
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// DynamicGroup of [Runner] whose members can be added & removed while it is
+// running, such as workers for tenants which come & go.
+//
+// Members are identified by a name which must be unique within the group.
+// Members added before the group is started are started in parallel along
+// with it and the group is ready once they are all ready (see [Readier]).
+// Members added while the group is running are started immediately. Stopping
+// the group stops all remaining members in series in the order they were
+// added.
+//
+// A member whose [Runner.Start] returns does not stop the group, instead
+// OnExit (if set) is called with its name and the error (if any) it returned.
+// The member remains in the group until it is removed. Its state remains
+// visible via [Monitor.Snapshot] until then, but it no longer affects
+// [Monitor.Readiness] or [Monitor.Liveness].
+//
+// DynamicGroup satisfies [Runner] and thus it can be nested within a [Group] or
+// itself. The zero value is ready to use and a DynamicGroup must not be copied
+// after first use.
+type DynamicGroup struct {
+	OnExit func(name string, err error)
+
+	mu       sync.Mutex
+	ctx      context.Context
+	running  bool
+	stopping bool
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+	members  []*member
+}
+
+type member struct {
+	name   string
+	runner Runner
+	doneCh chan struct{}
+}
+
+func (g *DynamicGroup) children() []child {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	children := make([]child, len(g.members))
+	for i, m := range g.members {
+		children[i] = child{name: m.name, runner: m.runner}
+	}
+
+	return children
+}
+
+// Add r to the group as name, starting it immediately if the group is
+// running. A [DuplicateMemberError] is returned if the group already has a
+// member named name and a [GroupStoppedError] is returned if the group has
+// been stopped.
+func (g *DynamicGroup) Add(name string, r Runner) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.stopping {
+		return &GroupStoppedError{}
+	}
+	if slices.ContainsFunc(g.members, func(m *member) bool { return m.name == name }) {
+		return &DuplicateMemberError{Name: name}
+	}
+
+	m := &member{name: name, runner: r}
+	g.members = append(g.members, m)
+	if g.running {
+		_ = g.launch(m)
+	}
+
+	return nil
+}
+
+// Remove the member named name from the group, stopping it then waiting for
+// its [Runner.Start] to return, after which the [Monitor] (if any) forgets its
+// state. An [UnknownMemberError] is returned if the group has no member named
+// name.
+func (g *DynamicGroup) Remove(ctx context.Context, name string) error {
+	g.mu.Lock()
+	i := slices.IndexFunc(g.members, func(m *member) bool { return m.name == name })
+	if i < 0 {
+		g.mu.Unlock()
+		return &UnknownMemberError{Name: name}
+	}
+	m := g.members[i]
+	g.members = slices.Delete(g.members, i, i+1)
+	runCtx := g.ctx
+	g.mu.Unlock()
+
+	if runCtx != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = withCancelOf(runCtx, ctx)
+		defer cancel()
+		defer monitor(runCtx).forget(path(childContext(runCtx, name)))
+	}
+
+	err := stopRunner(ctx, name, m.runner, 0)
+	if m.doneCh == nil {
+		return err
+	}
+
+	select {
+	case <-m.doneCh:
+		return err
+	case <-ctx.Done():
+		return cmp.Or(err, ctx.Err())
+	}
+}
+
+// Names returns the names of the members of the group in the order they were
+// added.
+func (g *DynamicGroup) Names() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	names := make([]string, len(g.members))
+	for i, m := range g.members {
+		names[i] = m.name
+	}
+
+	return names
+}
+
+// Start all members in parallel then block until the group is stopped or the
+// passed context is canceled. Once either occurs, blocks until the
+// [Runner.Start] of every member has returned.
+func (g *DynamicGroup) Start(ctx context.Context) error {
+	g.mu.Lock()
+	if g.stopping || g.running {
+		g.mu.Unlock()
+		return nil
+	}
+	if g.stopCh == nil {
+		g.stopCh = make(chan struct{})
+	}
+	g.ctx, g.running = ctx, true
+	readies := make([]<-chan error, 0, len(g.members))
+	for _, m := range g.members {
+		readies = append(readies, g.launch(m))
+	}
+	stopCh := g.stopCh
+	g.mu.Unlock()
+
+	go func() {
+		for _, ready := range readies {
+			if err := <-ready; err != nil {
+				return
+			}
+		}
+		MarkReady(ctx)
+	}()
+
+	var err error
+	select {
+	case <-stopCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	g.mu.Lock()
+	g.running = false
+	g.mu.Unlock()
+	g.wg.Wait()
+
+	return err
+}
+
+// Stop prevents any further members from being added then stops all members
+// in series in the order they were added. Blocks until all [Runner.Stop] have
+// returned normally, then returns the first non-nil error (if any) from them.
+func (g *DynamicGroup) Stop(ctx context.Context) error {
+	g.mu.Lock()
+	if !g.stopping {
+		g.stopping = true
+		if g.stopCh == nil {
+			g.stopCh = make(chan struct{})
+		}
+		close(g.stopCh)
+	}
+	runners := make([]Runner, len(g.members))
+	for i, m := range g.members {
+		runners[i] = Named(m.name, m.runner)
+	}
+	g.mu.Unlock()
+
+	return stopAll(ctx, runners, StopForward, 0)
+}
+
+// launch starts m, returning a channel which receives once it is ready. It
+// must be called with mu held while the group is running.
+func (g *DynamicGroup) launch(m *member) <-chan error {
+	ctx := g.ctx
+	start, ready := withReady(contain(ctx), m.name, m.runner)
+	m.doneCh = make(chan struct{})
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer close(m.doneCh)
+		err := start()
+		monitor(ctx).detach(path(childContext(ctx, m.name)))
+		if g.OnExit != nil {
+			g.OnExit(m.name, err)
+		}
+	}()
+
+	return ready
+}
+
+// DuplicateMemberError occurs when a runner is added to a [DynamicGroup] under
+// a name which is already in use.
+type DuplicateMemberError struct {
+	Name string
+}
+
+func (e *DuplicateMemberError) Error() string {
+	return fmt.Sprintf("duplicate member %q", e.Name)
+}
+
+// UnknownMemberError occurs when a runner which is not a member of a
+// [DynamicGroup] is removed from it.
+type UnknownMemberError struct {
+	Name string
+}
+
+func (e *UnknownMemberError) Error() string {
+	return fmt.Sprintf("unknown member %q", e.Name)
+}
+
+// GroupStoppedError occurs when a runner is added to a [DynamicGroup] which has
+// been stopped.
+type GroupStoppedError struct{}
+
+func (e *GroupStoppedError) Error() string {
+	return "group has been stopped"
+}
@@ -0,0 +1,229 @@
+package graceful_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wafer-bw/go-toolbox/graceful"
+)
+
+// member returns a [graceful.Runner] which blocks until it is stopped, sending
+// on startedCh once started and on stoppedCh once stopped.
+func member(name string, startedCh, stoppedCh chan<- string) graceful.Runner {
+	stopCh := make(chan struct{})
+	return graceful.RunnerType{
+		StartFunc: func(ctx context.Context) error {
+			startedCh <- name
+			select {
+			case <-stopCh:
+			case <-ctx.Done():
+			}
+			return nil
+		},
+		StopFunc: func(ctx context.Context) error {
+			close(stopCh)
+			stoppedCh <- name
+			return nil
+		},
+	}
+}
+
+func TestDynamicGroup(t *testing.T) {
+	t.Parallel()
+
+	t.Run("starts members added before & while running then stops all on shutdown", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		startedCh, stoppedCh := make(chan string, 3), make(chan string, 3)
+		g := &graceful.DynamicGroup{}
+		require.NoError(t, g.Add("a", member("a", startedCh, stoppedCh)))
+
+		errCh := make(chan error, 1)
+		go func() { errCh <- graceful.Run(ctx, g) }()
+		require.Equal(t, "a", <-startedCh)
+
+		require.NoError(t, g.Add("b", member("b", startedCh, stoppedCh)))
+		require.Equal(t, "b", <-startedCh)
+		require.Equal(t, []string{"a", "b"}, g.Names())
+
+		cancel()
+		require.ErrorIs(t, <-errCh, context.Canceled)
+		require.Equal(t, "a", <-stoppedCh)
+		require.Equal(t, "b", <-stoppedCh)
+
+		require.ErrorAs(t, g.Add("c", member("c", startedCh, stoppedCh)), new(*graceful.GroupStoppedError))
+	})
+
+	t.Run("stops removed members individually", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		startedCh, stoppedCh := make(chan string, 2), make(chan string, 2)
+		m := &graceful.Monitor{}
+		g := &graceful.DynamicGroup{}
+		require.NoError(t, g.Add("a", member("a", startedCh, stoppedCh)))
+		require.NoError(t, g.Add("b", member("b", startedCh, stoppedCh)))
+
+		errCh := make(chan error, 1)
+		go func() { errCh <- graceful.Run(ctx, g, graceful.WithMonitor(m)) }()
+		<-startedCh
+		<-startedCh
+
+		require.NoError(t, g.Remove(t.Context(), "a"))
+		require.Equal(t, "a", <-stoppedCh)
+		require.Equal(t, []string{"b"}, g.Names())
+		_, ok := m.State("a")
+		require.False(t, ok, "removed members are forgotten")
+
+		cancel()
+		require.ErrorIs(t, <-errCh, context.Canceled)
+		require.Equal(t, "b", <-stoppedCh)
+		require.Empty(t, stoppedCh)
+	})
+
+	t.Run("is ready once its initial members are ready", func(t *testing.T) {
+		t.Parallel()
+
+		readyCh := make(chan struct{})
+		g := &graceful.DynamicGroup{}
+		require.NoError(t, g.Add("a", graceful.RunnerType{
+			StartFunc: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			ReadyFunc: func(ctx context.Context) error {
+				<-readyCh
+				return nil
+			},
+		}))
+
+		m := &graceful.Monitor{}
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		errCh := make(chan error, 1)
+		go func() { errCh <- graceful.Run(ctx, g, graceful.WithMonitor(m)) }()
+
+		time.Sleep(10 * time.Millisecond)
+		root, ok := m.State("")
+		require.True(t, ok)
+		require.Equal(t, graceful.StateStarting, root.State)
+
+		close(readyCh)
+		require.Eventually(t, func() bool {
+			root, _ := m.State("")
+			return root.State == graceful.StateRunning
+		}, time.Second, time.Millisecond)
+
+		cancel()
+		<-errCh
+	})
+
+	t.Run("calls on exit when a member returns without stopping the group", func(t *testing.T) {
+		t.Parallel()
+
+		exitErr := errors.New("exited")
+		type exit struct {
+			name string
+			err  error
+		}
+		exitCh := make(chan exit, 1)
+		g := &graceful.DynamicGroup{
+			OnExit: func(name string, err error) { exitCh <- exit{name: name, err: err} },
+		}
+
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		errCh := make(chan error, 1)
+		go func() { errCh <- graceful.Run(ctx, g) }()
+
+		require.NoError(t, g.Add("a", graceful.RunnerType{
+			StartFunc: func(ctx context.Context) error { return exitErr },
+		}))
+		got := <-exitCh
+		require.Equal(t, "a", got.name)
+		require.ErrorIs(t, got.err, exitErr)
+		require.Empty(t, errCh)
+		require.Equal(t, []string{"a"}, g.Names())
+
+		cancel()
+		require.ErrorIs(t, <-errCh, context.Canceled)
+	})
+
+	t.Run("does not fail probes for members which exited", func(t *testing.T) {
+		t.Parallel()
+
+		exitErr := errors.New("exited")
+		exitCh := make(chan struct{}, 1)
+		m := &graceful.Monitor{}
+		g := &graceful.DynamicGroup{OnExit: func(string, error) { exitCh <- struct{}{} }}
+
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		errCh := make(chan error, 1)
+		go func() { errCh <- graceful.Run(ctx, g, graceful.WithMonitor(m)) }()
+		require.Eventually(t, func() bool { return m.Readiness().Probe(t.Context()) == nil }, time.Second, time.Millisecond)
+
+		require.NoError(t, g.Add("a", graceful.RunnerType{
+			StartFunc: func(ctx context.Context) error { return exitErr },
+		}))
+		<-exitCh
+		a, ok := m.State("a")
+		require.True(t, ok)
+		require.Equal(t, graceful.StateFailed, a.State)
+		require.NoError(t, m.Liveness().Probe(t.Context()))
+		require.NoError(t, m.Readiness().Probe(t.Context()))
+
+		require.NoError(t, g.Remove(t.Context(), "a"))
+		_, ok = m.State("a")
+		require.False(t, ok)
+
+		cancel()
+		require.ErrorIs(t, <-errCh, context.Canceled)
+	})
+
+	t.Run("returns errors for duplicate & unknown members", func(t *testing.T) {
+		t.Parallel()
+
+		g := &graceful.DynamicGroup{}
+		require.NoError(t, g.Add("a", graceful.RunnerType{}))
+
+		duplicateErr := &graceful.DuplicateMemberError{}
+		require.ErrorAs(t, g.Add("a", graceful.RunnerType{}), &duplicateErr)
+		require.Equal(t, "a", duplicateErr.Name)
+
+		unknownErr := &graceful.UnknownMemberError{}
+		require.ErrorAs(t, g.Remove(t.Context(), "b"), &unknownErr)
+		require.Equal(t, "b", unknownErr.Name)
+	})
+
+	t.Run("removes members which were never started", func(t *testing.T) {
+		t.Parallel()
+
+		stopped := make(chan struct{}, 1)
+		g := &graceful.DynamicGroup{}
+		require.NoError(t, g.Add("a", graceful.RunnerType{
+			StopFunc: func(ctx context.Context) error {
+				stopped <- struct{}{}
+				return nil
+			},
+		}))
+
+		require.NoError(t, g.Remove(t.Context(), "a"))
+		<-stopped
+		require.Empty(t, g.Names())
+	})
+}
+
+func TestDynamicGroup_errors(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, `duplicate member "a"`, (&graceful.DuplicateMemberError{Name: "a"}).Error())
+	require.Equal(t, `unknown member "a"`, (&graceful.UnknownMemberError{Name: "a"}).Error())
+	require.Equal(t, "group has been stopped", (&graceful.GroupStoppedError{}).Error())
+}
@@ -0,0 +1,102 @@
+package graceful
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// EventKind is the kind of lifecycle [Event] which occurred.
+type EventKind int
+
+const (
+	// EventStarting occurs when [Runner.Start] is about to be called.
+	EventStarting EventKind = iota
+	// EventStarted occurs when the runner is ready (see [Readier]) unless its
+	// [Runner.Start] has already returned an error.
+	EventStarted
+	// EventStartFailed occurs when [Runner.Start] returns an error.
+	EventStartFailed
+	// EventStopping occurs when [Runner.Stop] is about to be called.
+	EventStopping
+	// EventStopped occurs when [Runner.Stop] returns nil.
+	EventStopped
+	// EventStopFailed occurs when [Runner.Stop] returns an error.
+	EventStopFailed
+	// EventStopTimeout occurs when the context passed to [Runner.Stop] becomes
+	// done before it returns.
+	EventStopTimeout
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventStarting:
+		return "starting"
+	case EventStarted:
+		return "started"
+	case EventStartFailed:
+		return "start-failed"
+	case EventStopping:
+		return "stopping"
+	case EventStopped:
+		return "stopped"
+	case EventStopFailed:
+		return "stop-failed"
+	case EventStopTimeout:
+		return "stop-timeout"
+	default:
+		return "unknown"
+	}
+}
+
+// Event describes a change in the lifecycle of a [Runner] within a tree.
+type Event struct {
+	// Name of the runner (see [RunnerError]).
+	Name string
+	// Kind of event which occurred.
+	Kind EventKind
+	// Duration since [EventStarting] or [EventStopping] occurred for the
+	// runner.
+	Duration time.Duration
+	// Err which caused the event, if any.
+	Err error
+}
+
+// EventHook receives lifecycle [Event] from throughout a tree of [Runner]. It
+// must be safe for concurrent use.
+type EventHook func(Event)
+
+// WithEventHook sets the [EventHook] which [Run] will pass lifecycle [Event]
+// to.
+func WithEventHook(hook EventHook) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.eventHook = hook
+	}
+}
+
+// SlogEventHook returns an [EventHook] which logs each [Event] to logger.
+//
+// Failures are logged at [slog.LevelError], timeouts at [slog.LevelWarn], and
+// everything else at [slog.LevelInfo].
+func SlogEventHook(logger *slog.Logger) EventHook {
+	return func(e Event) {
+		level := slog.LevelInfo
+		switch e.Kind {
+		case EventStartFailed, EventStopFailed:
+			level = slog.LevelError
+		case EventStopTimeout:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("runner", e.Name),
+			slog.String("event", e.Kind.String()),
+			slog.Duration("duration", e.Duration),
+		}
+		if e.Err != nil {
+			attrs = append(attrs, slog.String("error", e.Err.Error()))
+		}
+
+		logger.LogAttrs(context.Background(), level, "runner "+e.Kind.String(), attrs...)
+	}
+}
@@ -0,0 +1,189 @@
+package graceful_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wafer-bw/go-toolbox/graceful"
+)
+
+// eventRecorder records lifecycle events passed to its hook.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []graceful.Event
+}
+
+func (r *eventRecorder) Hook(e graceful.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+// Kinds returns the kinds of events recorded for the runner named name.
+func (r *eventRecorder) Kinds(name string) []graceful.EventKind {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var kinds []graceful.EventKind
+	for _, e := range r.events {
+		if e.Name == name {
+			kinds = append(kinds, e.Kind)
+		}
+	}
+	return kinds
+}
+
+func TestWithEventHook(t *testing.T) {
+	t.Parallel()
+
+	t.Run("emits lifecycle events for named runners", func(t *testing.T) {
+		t.Parallel()
+
+		rec := &eventRecorder{}
+		ctx, cancel := context.WithCancel(t.Context())
+		g := graceful.Group{
+			graceful.Named("db", graceful.RunnerType{}),
+			graceful.Named("api", graceful.Group{
+				graceful.RunnerType{},
+			}),
+		}
+
+		err := graceful.Run(ctx, g, graceful.WithEventHook(func(e graceful.Event) {
+			rec.Hook(e)
+			if e.Name == "" && e.Kind == graceful.EventStarted {
+				cancel()
+			}
+		}))
+		require.ErrorIs(t, err, context.Canceled)
+
+		lifecycle := []graceful.EventKind{
+			graceful.EventStarting,
+			graceful.EventStarted,
+			graceful.EventStopping,
+			graceful.EventStopped,
+		}
+		require.Equal(t, lifecycle, rec.Kinds(""))
+		require.Equal(t, lifecycle, rec.Kinds("db"))
+		require.Equal(t, lifecycle, rec.Kinds("api"))
+		require.Equal(t, lifecycle, rec.Kinds("api/0"))
+	})
+
+	t.Run("emits failure events", func(t *testing.T) {
+		t.Parallel()
+
+		rec := &eventRecorder{}
+		startErr, stopErr := errors.New("start failed"), errors.New("stop failed")
+		g := graceful.Group{
+			graceful.Named("db", graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error { return startErr },
+				StopFunc:  func(ctx context.Context) error { return stopErr },
+			}),
+		}
+
+		err := graceful.Run(t.Context(), g, graceful.WithEventHook(rec.Hook))
+		require.ErrorIs(t, err, startErr)
+		require.Equal(t, []graceful.EventKind{
+			graceful.EventStarting,
+			graceful.EventStartFailed,
+			graceful.EventStopping,
+			graceful.EventStopFailed,
+		}, rec.Kinds("db"))
+	})
+
+	t.Run("emits stop timeout event when stop context is done first", func(t *testing.T) {
+		t.Parallel()
+
+		rec := &eventRecorder{}
+		startErr := errors.New("start failed")
+		g := graceful.Group{
+			graceful.Named("db", graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error { return startErr },
+				StopFunc: func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				},
+			}),
+		}
+
+		err := graceful.Run(t.Context(), g,
+			graceful.WithStopTimeout(10*time.Millisecond),
+			graceful.WithEventHook(rec.Hook),
+		)
+		require.ErrorIs(t, err, startErr)
+		require.Equal(t, []graceful.EventKind{
+			graceful.EventStarting,
+			graceful.EventStartFailed,
+			graceful.EventStopping,
+			graceful.EventStopTimeout,
+			graceful.EventStopFailed,
+		}, rec.Kinds("db"))
+	})
+
+	t.Run("does not emit stop timeout event when stop returns first", func(t *testing.T) {
+		t.Parallel()
+
+		for range 20 {
+			rec := &eventRecorder{}
+			startErr := errors.New("start failed")
+			g := graceful.Group{
+				graceful.Named("db", graceful.RunnerType{
+					StartFunc: func(ctx context.Context) error { return startErr },
+				}),
+			}
+
+			err := graceful.Run(t.Context(), g,
+				graceful.WithStopTimeout(time.Second),
+				graceful.WithEventHook(rec.Hook),
+			)
+			require.ErrorIs(t, err, startErr)
+			time.Sleep(time.Millisecond) // let any late event through.
+			require.NotContains(t, rec.Kinds("db"), graceful.EventStopTimeout)
+			require.NotContains(t, rec.Kinds(""), graceful.EventStopTimeout)
+		}
+	})
+}
+
+func TestEventKind_String(t *testing.T) {
+	t.Parallel()
+
+	for kind, want := range map[graceful.EventKind]string{
+		graceful.EventStarting:    "starting",
+		graceful.EventStarted:     "started",
+		graceful.EventStartFailed: "start-failed",
+		graceful.EventStopping:    "stopping",
+		graceful.EventStopped:     "stopped",
+		graceful.EventStopFailed:  "stop-failed",
+		graceful.EventStopTimeout: "stop-timeout",
+		graceful.EventKind(-1):    "unknown",
+	} {
+		require.Equal(t, want, kind.String())
+	}
+}
+
+func TestSlogEventHook(t *testing.T) {
+	t.Parallel()
+
+	t.Run("logs events", func(t *testing.T) {
+		t.Parallel()
+
+		buf := &bytes.Buffer{}
+		hook := graceful.SlogEventHook(slog.New(slog.NewTextHandler(buf, nil)))
+
+		hook(graceful.Event{Name: "db", Kind: graceful.EventStopped, Duration: time.Second})
+		hook(graceful.Event{Name: "api", Kind: graceful.EventStopFailed, Err: errors.New("oh no")})
+		hook(graceful.Event{Name: "cache", Kind: graceful.EventStopTimeout})
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 3)
+		require.Contains(t, lines[0], `level=INFO msg="runner stopped" runner=db event=stopped duration=1s`)
+		require.Contains(t, lines[1], `level=ERROR msg="runner stop-failed" runner=api event=stop-failed duration=0s error="oh no"`)
+		require.Contains(t, lines[2], `level=WARN msg="runner stop-timeout" runner=cache`)
+	})
+}
@@ -3,15 +3,24 @@
 // shutdowns.
 //
 // This package handles the common case of starting several things in parallel
-// then stopping them gracefully in series. It does not act as a full directed
-// acyclic graph (https://en.wikipedia.org/wiki/Directed_acyclic_graph).
+// then stopping them gracefully in series via [Group], starting things in
+// ordered stages which wait for readiness via [Stages], and starting &
+// stopping things according to their dependencies on each other via [Graph].
+//
+// Ready-made runners are provided for common long running tasks such as
+// serving HTTP via [HTTPServer], accepting connections via [Listener], calling
+// a task periodically via [Ticker], and handling jobs via [WorkerPool].
 package graceful
 
 import (
 	"cmp"
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"time"
 
 	"golang.org/x/sync/errgroup"
@@ -30,6 +39,35 @@ type Runner interface {
 	Stop(context.Context) error
 }
 
+// Readier is optionally implemented by a [Runner] which is not ready to be
+// depended upon as soon as its [Runner.Start] has been called, such as one
+// which must first connect to a database or warm a cache.
+//
+// A [Runner] which does not implement Readier may instead be wrapped via
+// [AwaitReady] and call [MarkReady] from within its [Runner.Start]. Any other
+// [Runner] is ready as soon as its [Runner.Start] has been called. A [Runner]
+// whose [Runner.Start] returns nil is considered ready.
+type Readier interface {
+	// Ready must terminate when the passed context is canceled or the runner
+	// is ready (whichever happens first). A nil error means the runner is
+	// ready.
+	Ready(context.Context) error
+}
+
+type readyKey struct{}
+
+// MarkReady marks the [Runner] whose [Runner.Start] was passed ctx as ready.
+//
+// It is safe to call more than once and is a no-op when nothing is waiting
+// for the runner to be ready. It only has an effect for a [Runner] wrapped via
+// [AwaitReady] and the runners of this package which document when they are
+// ready.
+func MarkReady(ctx context.Context) {
+	if markReady, ok := ctx.Value(readyKey{}).(func()); ok {
+		markReady()
+	}
+}
+
 type RunOption func(*RunConfig)
 
 func WithStopTimeout(d time.Duration) RunOption {
@@ -50,64 +88,184 @@ func WithStoppingCh(ch chan<- struct{}) RunOption {
 	}
 }
 
+// WithJoinedErrors makes [Run] return every error encountered throughout the
+// tree of [Runner] rather than only the first. Each [Runner.Start],
+// [Runner.Stop], & [Readier.Ready] error is wrapped in a [RunnerError]
+// identifying the runner and they are joined together via [errors.Join]. Unless
+// shutdown is forced, [Run] waits for the tree to return from [Runner.Start]
+// so that errors returned while stopping are not missed.
+func WithJoinedErrors() RunOption {
+	return func(cfg *RunConfig) {
+		cfg.joinErrors = true
+	}
+}
+
+// WithStopBudget makes each [Group], [OrderedGroup], [Stages], [Supervisor], &
+// [DynamicGroup] which stops its [Runner] in series allot each of them an equal
+// share of the time remaining before the stop deadline (see [WithStopTimeout])
+// for the runners it has yet to stop. This guarantees each runner at least its
+// share of the overall deadline regardless of how slow the runners stopped
+// before it are. A [Runner.Stop] which exceeds its share returns a
+// [StopTimeoutError].
+func WithStopBudget() RunOption {
+	return func(cfg *RunConfig) {
+		cfg.stopBudget = true
+	}
+}
+
+// WithForceSignals sets the signals which force an immediate shutdown when
+// received once stopping has been initiated. Forcing a shutdown cancels the
+// [context.Context] passed to each [Runner.Stop] and makes [Run] return a
+// [ForcedShutdownError].
+//
+// Passing the same signals as [WithStopSignals] makes a repeated stop signal
+// force the shutdown.
+func WithForceSignals(signals ...os.Signal) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.forceSignals = signals
+	}
+}
+
+// WithForceExit makes [Run] call [os.Exit] with a status code of 1 if the
+// [Runner.Stop] calls have not returned within d of a shutdown being forced via
+// a signal passed to [WithForceSignals].
+func WithForceExit(d time.Duration) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.forceExitAfter = d
+	}
+}
+
+// WithStartTimeout makes [Run] initiate stopping with a [StartTimeoutError] if
+// the tree of [Runner] is not ready within d of being started. Only a
+// [Runner] which implements [Readier] or is wrapped via [AwaitReady] can delay
+// the tree from being ready, any other is ready as soon as its [Runner.Start]
+// has been called.
+func WithStartTimeout(d time.Duration) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.startTimeout = d
+	}
+}
+
+// WithPreStopDelay makes [Run] wait for d once stopping has been initiated but
+// before calling [Runner.Stop], giving load balancers time to stop routing
+// traffic to the process while it is still serving.
+//
+// The channel passed via [WithStoppingCh] is closed before waiting so that
+// readiness probes which observe it, such as [Monitor.Readiness], start
+// failing. Waiting is cut short when the passed context is canceled or another
+// signal passed via [WithStopSignals] or [WithForceSignals] is received.
+func WithPreStopDelay(d time.Duration) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.preStopDelay = d
+	}
+}
+
 type RunConfig struct {
 	stopTimeout time.Duration
 	signals     []os.Signal
 	stoppingCh  chan<- struct{}
+	joinErrors  bool
+	eventHook   EventHook
+	stopBudget  bool
+
+	forceSignals   []os.Signal
+	forceExitAfter time.Duration
+	preStopDelay   time.Duration
+	exit           func(code int)
+
+	reloadSignals   []os.Signal
+	parallelReload  bool
+	reloadErrorHook func(err error)
+
+	monitor      *Monitor
+	startTimeout time.Duration
+
+	systemdNotify bool
+
+	listeners        *Listeners
+	handoffSignals   []os.Signal
+	handoffErrorHook func(err error)
+
+	completion bool
 }
 
-// Group of [Runner] which can be started in parallel & stopped in series.
+// Group of [Runner] which can be started in parallel & stopped in series. See
+// [OrderedGroup] to stop in a different order.
 //
 // Group satisfies [Runner] and thus it can be nested within itself to create
-// a tree.
+// a tree. A Group is ready once all of its [Runner] are ready (see [Readier]).
 type Group []Runner
 
 // Start all [Runner] in parallel. Blocks until all [Runner.Start] have returned
 // normally, then returns the first non-nil errror (if any) from them.
 func (g Group) Start(ctx context.Context) error {
 	eg := new(errgroup.Group)
-	for _, r := range g {
+	readies := make([]<-chan error, 0, len(g))
+	for i, r := range g {
 		if r == nil {
 			continue
 		}
-		eg.Go(func() error { return r.Start(ctx) })
+		start, ready := withReady(ctx, nameOf(r, i), r)
+		readies = append(readies, ready)
+		eg.Go(start)
 	}
 
+	go func() {
+		for _, ready := range readies {
+			if err := <-ready; err != nil {
+				return
+			}
+		}
+		MarkReady(ctx)
+	}()
+
 	return eg.Wait()
 }
 
 // Stop all [Runner] in series. Blocks until all [Runner.Stop] have returned
 // normally, then returns the first non-nil errror (if any) from them.
 func (g Group) Stop(ctx context.Context) error {
-	var firstErr error
-	for _, r := range g {
-		if r == nil {
-			continue
-		}
-		if err := r.Stop(ctx); err != nil && firstErr == nil {
-			firstErr = err
-		}
-	}
-
-	return firstErr
+	return stopAll(ctx, g, StopForward, 0)
 }
 
 // Run starts all [Runner] in parallel and stops them in series.
 //
+// It is shorthand for calling [Run] with g.
+func (g Group) Run(ctx context.Context, opts ...RunOption) error {
+	return Run(ctx, g, opts...)
+}
+
+// Run starts r and stops it once stopping is initiated.
+//
 // Stopping is initiated when any of the following occurs:
 //   - the passed context is canceled
 //   - a signal passed via [WithStopSignals] is received
-//   - a [Runner.Start] returns an error
+//   - listeners are handed off via a signal passed to [WithHandoff]
+//   - a [Runner.Start] or [Readier.Ready] returns an error, unless it is a
+//     child of a [Supervisor] or member of a [DynamicGroup] which handle their
+//     failures
+//   - the tree does not start within the timeout passed via
+//     [WithStartTimeout]
+//   - every task has completed when [WithCompletion] is used
 //
 // When stopping is initiated, the channel passed via [WithStoppingCh] will be
 // closed. It will use the timeout passed via [WithStopTimeout] as the deadline
-// for the [context.Context] passed to each [Runner.Stop].
+// for the [context.Context] passed to each [Runner.Stop]. Calling
+// [Runner.Stop] can be delayed via [WithPreStopDelay] and stopping can be cut
+// short via [WithForceSignals].
 //
-// The first encountered error (either [Runner.Start] error,
-// [context.Context.Err], or [Runner.Stop] error) will be returned. However, all
-// [Runner.Stop] are guaranteed to be called.
-func (g Group) Run(ctx context.Context, opts ...RunOption) error {
-	cfg := &RunConfig{}
+// The [context.Context] passed to each [Runner.Start] is canceled with a
+// [ShutdownReason] describing why stopping was initiated as its cause. This
+// happens immediately when the passed context is canceled, otherwise once all
+// [Runner.Stop] have returned.
+//
+// The first encountered error (either a [ShutdownReason] wrapping the
+// [Runner.Start] error or [context.Cause] of the passed context, or a
+// [Runner.Stop] error) will be returned unless [WithJoinedErrors] is used.
+// However, all [Runner.Stop] are guaranteed to be called. Stopping due to a
+// signal or completion is not an error.
+func Run(ctx context.Context, r Runner, opts ...RunOption) error {
+	cfg := &RunConfig{exit: os.Exit}
 	for _, opt := range opts {
 		if opt == nil {
 			continue
@@ -115,31 +273,86 @@ func (g Group) Run(ctx context.Context, opts ...RunOption) error {
 		opt(cfg)
 	}
 
-	var startErr, runErr error
-	startErrCh := make(chan error)
+	if cfg.monitor == nil {
+		cfg.monitor = &Monitor{}
+	}
+	state := &runState{cfg: cfg, reasonCh: make(chan *ShutdownReason, 1)}
+	ctx = context.WithValue(ctx, runStateKey{}, state)
+	if cfg.completion {
+		state.completion = newCompletion(ctx, r)
+	}
+	startCtx, cancelStart := context.WithCancelCause(context.WithoutCancel(ctx))
+	defer cancelStart(nil)
+	stopCanceling := context.AfterFunc(ctx, func() {
+		cancelStart(&ShutdownReason{Canceled: true, Err: context.Cause(ctx)})
+	})
+	defer stopCanceling()
+
+	start, readyCh := withReady(startCtx, "", r)
+	readies := tee(readyCh, 3)
+	startDoneCh := make(chan struct{})
 	go func() {
-		if err := g.Start(ctx); err != nil {
-			startErrCh <- err
-		}
+		defer close(startDoneCh)
+		_ = start()
 	}()
+	watchCtx, stopWatching := context.WithCancel(startCtx)
+	startTimeoutCh := watchStartTimeout(watchCtx, cfg.startTimeout, readies[0])
+	notifier := startNotifier(ctx, cfg, readies[1])
+	defer notifier.close()
 
 	signalCh := make(chan os.Signal, 1)
 	if len(cfg.signals) != 0 {
 		signal.Notify(signalCh, cfg.signals...)
 	}
+	stopReloads := watchReloads(ctx, r, cfg)
+	handoffCh, stopHandoffs := watchHandoffs(ctx, cfg, readies[2])
+	var reason *ShutdownReason
 	select {
-	case <-signalCh:
-		// received signal
-	case err := <-startErrCh:
-		startErr = err
+	case sig := <-signalCh:
+		reason = &ShutdownReason{Signal: sig}
+	case sig := <-handoffCh:
+		reason = &ShutdownReason{Signal: sig}
+	case <-state.completion.done():
+		reason = &ShutdownReason{Completed: true}
+	case reason = <-state.reasonCh:
+	case err := <-startTimeoutCh:
+		reason = &ShutdownReason{Err: err}
 	case <-ctx.Done():
-		runErr = ctx.Err()
 	}
-	signal.Stop(signalCh)
+	if ctx.Err() != nil && (reason == nil || reason.Signal == nil) {
+		reason = &ShutdownReason{Canceled: true, Err: context.Cause(ctx)}
+	}
+	state.setReason(reason)
+	stopReloads()
+	stopHandoffs()
+	stopWatching()
 
+	// listen for force signals before no longer listening for stop signals so
+	// that a repeated signal is not missed.
+	forceCh := make(chan os.Signal, 1)
+	if len(cfg.forceSignals) != 0 {
+		signal.Notify(forceCh, cfg.forceSignals...)
+		defer signal.Stop(forceCh)
+	}
+
+	cfg.monitor.markStopping()
+	notifier.stopping()
 	if cfg.stoppingCh != nil {
 		close(cfg.stoppingCh)
 	}
+	preStop(ctx, cfg.preStopDelay, signalCh, forceCh)
+
+	signal.Stop(signalCh)
+	select {
+	case sig := <-signalCh:
+		if slices.Contains(cfg.forceSignals, sig) {
+			select {
+			case forceCh <- sig:
+			default:
+			}
+		}
+	default:
+	}
 
 	stopCtx, cancel := context.WithTimeout(ctx, cfg.stopTimeout)
 	if cfg.stopTimeout == 0 {
@@ -147,18 +360,124 @@ func (g Group) Run(ctx context.Context, opts ...RunOption) error {
 	}
 	defer cancel()
 
-	stopErr := g.Stop(stopCtx)
+	stopCtx, force := context.WithCancelCause(stopCtx)
+	defer force(nil)
+
+	var stopErr error
+	var forcedErr *ForcedShutdownError
+	stopErrCh := make(chan error, 1)
+	go func() { stopErrCh <- stopRunner(stopCtx, "", r, 0) }()
+	select {
+	case stopErr = <-stopErrCh:
+	case sig := <-forceCh:
+		forcedErr = &ForcedShutdownError{Signal: sig}
+		force(&ForcedShutdownError{Signal: sig})
+		stopErr = awaitForcedStop(cfg, stopErrCh)
+	}
+	cancelStart(reason)
+	if cfg.joinErrors && forcedErr == nil {
+		// start errors are collected once the tree has returned from Start,
+		// which it must now that its context is canceled.
+		<-startDoneCh
+	}
+
+	var startErr, runErr error
+	switch {
+	case reason.Canceled:
+		runErr = reason
+	case reason.Signal == nil && !reason.Completed:
+		startErr = reason
+	}
+
+	err := cmp.Or(startErr, stopErr, runErr)
+	if cfg.joinErrors {
+		err = errors.Join(append(state.errors(), runErr)...)
+	}
+	if forcedErr != nil {
+		forcedErr.Err = err
+		return forcedErr
+	}
 
-	return cmp.Or(startErr, stopErr, runErr)
+	return err
 }
 
-// RunnerType is an adapter type to allow the use of ordinary start and stop
-// functions as a [Runner].
+// watchStartTimeout returns a channel which receives a [StartTimeoutError] if
+// the tree is not ready (see [Readier]) within d of being started. It never
+// receives if d is less than or equal to 0 or once ctx is canceled.
+func watchStartTimeout(ctx context.Context, d time.Duration, readyCh <-chan error) <-chan error {
+	timeoutCh := make(chan error, 1)
+	if d <= 0 {
+		return timeoutCh
+	}
+
+	go func() {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+
+		select {
+		case <-readyCh:
+		case <-ctx.Done():
+		case <-timer.C:
+			err := &StartTimeoutError{Timeout: d, Pending: monitor(ctx).pending()}
+			report(ctx, "start", err)
+			timeoutCh <- err
+		}
+	}()
+
+	return timeoutCh
+}
+
+// preStop waits for d before stopping begins. Waiting is cut short if ctx is
+// canceled or a stop or force signal is received. A force signal is left on
+// forceCh so that it still forces the shutdown.
+func preStop(ctx context.Context, d time.Duration, signalCh <-chan os.Signal, forceCh chan os.Signal) {
+	if d <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-signalCh:
+	case sig := <-forceCh:
+		forceCh <- sig
+	case <-ctx.Done():
+	}
+}
+
+// awaitForcedStop waits for the result of stopping after a shutdown was forced,
+// exiting if configured via [WithForceExit] and it takes too long.
+func awaitForcedStop(cfg *RunConfig, stopErrCh <-chan error) error {
+	if cfg.forceExitAfter <= 0 {
+		return <-stopErrCh
+	}
+
+	timer := time.NewTimer(cfg.forceExitAfter)
+	defer timer.Stop()
+
+	select {
+	case err := <-stopErrCh:
+		return err
+	case <-timer.C:
+		cfg.exit(1)
+		return nil
+	}
+}
+
+// RunnerType is an adapter type to allow the use of ordinary start, stop,
+// ready, and reload functions as a [Runner], [Readier], and [Reloader].
 //   - A nil StartFunc will immediately return nil.
 //   - A nil StopFunc will immediately return nil.
+//   - A nil ReadyFunc will immediately return nil and the runner is treated as
+//     if it did not implement [Readier], see [AwaitReady].
+//   - A nil ReloadFunc will immediately return nil.
 type RunnerType struct {
-	StartFunc func(context.Context) error
-	StopFunc  func(context.Context) error
+	StartFunc  func(context.Context) error
+	StopFunc   func(context.Context) error
+	ReadyFunc  func(context.Context) error
+	ReloadFunc func(context.Context) error
 }
 
 func (r RunnerType) Start(ctx context.Context) error {
@@ -174,3 +493,232 @@ func (r RunnerType) Stop(ctx context.Context) error {
 	}
 	return r.StopFunc(ctx)
 }
+
+func (r RunnerType) Ready(ctx context.Context) error {
+	if r.ReadyFunc == nil {
+		return nil
+	}
+	return r.ReadyFunc(ctx)
+}
+
+func (r RunnerType) Reload(ctx context.Context) error {
+	if r.ReloadFunc == nil {
+		return nil
+	}
+	return r.ReloadFunc(ctx)
+}
+
+// RunnerError wraps an error returned by a [Runner] within a tree along with
+// the operation which returned it and the name of the runner.
+//
+// The name of a runner is its path within the tree, with the name of each
+// nested runner separated by "/". Runners within a [Graph] are named by their
+// [Node] name. Runners within a [Group] or [Stages] are named by their index
+// unless they have been named via [Named].
+type RunnerError struct {
+	Name string
+	Op   string
+	Err  error
+}
+
+func (e *RunnerError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("%s: %v", e.Op, e.Err)
+	}
+	return fmt.Sprintf("%s %s: %v", e.Op, e.Name, e.Err)
+}
+
+func (e *RunnerError) Unwrap() error {
+	return e.Err
+}
+
+// StartTimeoutError occurs when a tree of [Runner] is not ready within the
+// timeout passed via [WithStartTimeout]. Pending holds the names of the
+// runners which had not yet started (see [RunnerError]).
+type StartTimeoutError struct {
+	Timeout time.Duration
+	Pending []string
+}
+
+func (e *StartTimeoutError) Error() string {
+	msg := fmt.Sprintf("not started within %s", e.Timeout)
+	if len(e.Pending) != 0 {
+		msg += fmt.Sprintf(": waiting on %s", strings.Join(e.Pending, ", "))
+	}
+
+	return msg
+}
+
+// PanicError occurs when a [Runner.Start], [Runner.Stop], [Readier.Ready], or
+// [Reloader.Reload] panics. The panic is recovered and the PanicError is
+// returned in place of the error the method would have returned, so the rest
+// of the tree is still stopped normally. Value is the value passed to panic
+// and Stack is the stack trace of the goroutine which panicked.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns Value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// ForcedShutdownError occurs when a shutdown is forced via a signal passed to
+// [WithForceSignals]. Err is the error (if any) which [Run] would have
+// otherwise returned.
+type ForcedShutdownError struct {
+	Signal os.Signal
+	Err    error
+}
+
+func (e *ForcedShutdownError) Error() string {
+	msg := fmt.Sprintf("shutdown forced by signal %s", e.Signal)
+	if e.Err != nil {
+		msg += fmt.Sprintf(": %v", e.Err)
+	}
+
+	return msg
+}
+
+func (e *ForcedShutdownError) Unwrap() error {
+	return e.Err
+}
+
+// ShutdownReason describes why [Run] initiated stopping. It is the cause of the
+// [context.Context] passed to each [Runner.Start] once canceled (see
+// [context.Cause]) and is returned from [Run] unless stopping was initiated by
+// a signal or completion.
+//
+// Exactly one of the following describes the reason:
+//   - Signal is the signal passed via [WithStopSignals] or [WithHandoff] which
+//     was received.
+//   - Completed is true when every task completed (see [WithCompletion]).
+//   - Canceled is true when the context passed to [Run] was canceled, in which
+//     case Err is its cause.
+//   - Otherwise Err is the error a [Runner.Start] returned, in which case
+//     Runner is the name of the runner, or the [StartTimeoutError].
+type ShutdownReason struct {
+	Signal    os.Signal
+	Completed bool
+	Canceled  bool
+	Runner    string
+	Err       error
+}
+
+func (e *ShutdownReason) Error() string {
+	switch {
+	case e.Signal != nil:
+		return fmt.Sprintf("received signal %s", e.Signal)
+	case e.Completed:
+		return "completed"
+	case e.Canceled:
+		return fmt.Sprintf("parent context canceled: %v", e.Err)
+	case e.Runner != "":
+		return fmt.Sprintf("runner %s failed: %v", e.Runner, e.Err)
+	default:
+		return fmt.Sprintf("%v", e.Err)
+	}
+}
+
+func (e *ShutdownReason) Unwrap() error {
+	return e.Err
+}
+
+// Named returns a [Runner] which wraps r, naming it within its parent [Group]
+// or [Stages] for use in [RunnerError] and [Event].
+//
+// A [Runner] may instead name itself by implementing a Name() string method.
+func Named(name string, r Runner) Runner {
+	return named{name: name, runner: r}
+}
+
+type named struct {
+	name   string
+	runner Runner
+}
+
+func (n named) Start(ctx context.Context) error { return n.runner.Start(ctx) }
+func (n named) Stop(ctx context.Context) error  { return n.runner.Stop(ctx) }
+func (n named) Name() string                    { return n.name }
+func (n named) Unwrap() Runner                  { return n.runner }
+
+// AwaitReady returns a [Runner] which wraps r, which calls [MarkReady] from
+// within its [Runner.Start], such that it is only considered ready once it
+// does so or its Start returns nil (see [Readier]).
+func AwaitReady(r Runner) Runner {
+	return awaitReady{runner: r}
+}
+
+type awaitReady struct {
+	runner Runner
+}
+
+func (a awaitReady) Start(ctx context.Context) error { return a.runner.Start(ctx) }
+func (a awaitReady) Stop(ctx context.Context) error  { return a.runner.Stop(ctx) }
+func (a awaitReady) Unwrap() Runner                  { return a.runner }
+func (a awaitReady) marksReady()                     {}
+
+// readyMarker is implemented by a [Runner] which calls [MarkReady] once it is
+// ready.
+type readyMarker interface {
+	marksReady()
+}
+
+// StopTimeout returns a [Runner] which wraps r, giving its [Runner.Stop] at
+// most d to return before a [StopTimeoutError] is returned.
+func StopTimeout(d time.Duration, r Runner) Runner {
+	return stopTimeout{timeout: d, runner: r}
+}
+
+type stopTimeout struct {
+	timeout time.Duration
+	runner  Runner
+}
+
+func (s stopTimeout) Start(ctx context.Context) error { return s.runner.Start(ctx) }
+func (s stopTimeout) Unwrap() Runner                  { return s.runner }
+
+func (s stopTimeout) Stop(ctx context.Context) error {
+	timeoutErr := &StopTimeoutError{Name: path(ctx), Allotted: s.timeout}
+	ctx, cancel := context.WithTimeoutCause(ctx, s.timeout, timeoutErr)
+	defer cancel()
+
+	err := s.runner.Stop(ctx)
+	if context.Cause(ctx) == error(timeoutErr) {
+		timeoutErr.Err = err
+		return timeoutErr
+	}
+
+	return err
+}
+
+// StopTimeoutError occurs when a [Runner.Stop] does not return within the time
+// allotted to it via [StopTimeout] or [WithStopBudget]. Name is the name of
+// the runner (see [RunnerError]) and Err is the error (if any) returned by it.
+type StopTimeoutError struct {
+	Name     string
+	Allotted time.Duration
+	Err      error
+}
+
+func (e *StopTimeoutError) Error() string {
+	msg := fmt.Sprintf("exceeded allotted stop time of %s", e.Allotted)
+	if e.Name != "" {
+		msg = fmt.Sprintf("runner %s %s", e.Name, msg)
+	}
+	if e.Err != nil {
+		msg += fmt.Sprintf(": %v", e.Err)
+	}
+
+	return msg
+}
+
+func (e *StopTimeoutError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,18 @@
+package graceful
+
+import "os/exec"
+
+// export for testing.
+func WithExit(exit func(code int)) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.exit = exit
+	}
+}
+
+func SetHandoffCommand(l *Listeners, command func() *exec.Cmd) {
+	l.command = command
+}
+
+func SetBeforeRestart(s *Supervisor, fn func()) {
+	s.beforeRestart = fn
+}
@@ -309,7 +309,7 @@ func TestGroup_Run(t *testing.T) {
 		}
 
 		err := g.Run(ctx)
-		require.Equal(t, ctx.Err(), err)
+		require.ErrorIs(t, err, ctx.Err())
 		_, aStartOpen := <-aStartCh
 		require.False(t, aStartOpen)
 		_, aStopOpen := <-aStopCh
@@ -349,7 +349,7 @@ func TestGroup_Run(t *testing.T) {
 		}
 
 		err := g.Run(ctx)
-		require.Equal(t, fail, err)
+		require.ErrorIs(t, err, fail)
 		_, aStartOpen := <-aStartCh
 		require.False(t, aStartOpen)
 		_, aStopOpen := <-aStopCh
@@ -389,7 +389,7 @@ func TestGroup_Run(t *testing.T) {
 		}
 
 		err := g.Run(ctx)
-		require.Equal(t, fail, err)
+		require.ErrorIs(t, err, fail)
 		_, aStartOpen := <-aStartCh
 		require.False(t, aStartOpen)
 		_, aStopOpen := <-aStopCh
@@ -466,7 +466,7 @@ func TestGroup_Run(t *testing.T) {
 		}
 
 		err := g.Run(ctx, graceful.WithStopTimeout(50*time.Millisecond))
-		require.Equal(t, ctx.Err(), err)
+		require.ErrorIs(t, err, ctx.Err())
 	})
 
 	t.Run("closes stopping channel", func(t *testing.T) {
@@ -543,7 +543,7 @@ func TestGroup_Run(t *testing.T) {
 
 		require.NotPanics(t, func() {
 			err := g.Run(ctx, graceful.WithStopSignals())
-			require.Equal(t, ctx.Err(), err)
+			require.ErrorIs(t, err, ctx.Err())
 		})
 	})
 
@@ -560,7 +560,7 @@ func TestGroup_Run(t *testing.T) {
 
 		require.NotPanics(t, func() {
 			err := g.Run(ctx, nil)
-			require.Equal(t, ctx.Err(), err)
+			require.ErrorIs(t, err, ctx.Err())
 		})
 	})
 
@@ -577,7 +577,7 @@ func TestGroup_Run(t *testing.T) {
 
 		err := g.Run(ctx)
 		require.Error(t, err)
-		require.Equal(t, startErr, err)
+		require.ErrorIs(t, err, startErr)
 	})
 
 	t.Run("returns stop error when there is a run error", func(t *testing.T) {
@@ -596,3 +596,828 @@ func TestGroup_Run(t *testing.T) {
 		require.Equal(t, stopErr, err)
 	})
 }
+
+func TestRunnerType_Ready(t *testing.T) {
+	t.Parallel()
+
+	t.Run("does not panic when ReadyFunc is nil", func(t *testing.T) {
+		t.Parallel()
+
+		r := graceful.RunnerType{}
+		require.NotPanics(t, func() {
+			err := r.Ready(t.Context())
+			require.NoError(t, err)
+		})
+	})
+
+	t.Run("returns ReadyFunc error", func(t *testing.T) {
+		t.Parallel()
+
+		readyErr := errors.New("not ready")
+		r := graceful.RunnerType{ReadyFunc: func(ctx context.Context) error { return readyErr }}
+		err := r.Ready(t.Context())
+		require.ErrorIs(t, err, readyErr)
+	})
+}
+
+func TestRunnerType_Reload(t *testing.T) {
+	t.Parallel()
+
+	t.Run("does not panic when ReloadFunc is nil", func(t *testing.T) {
+		t.Parallel()
+
+		r := graceful.RunnerType{}
+		require.NotPanics(t, func() {
+			err := r.Reload(t.Context())
+			require.NoError(t, err)
+		})
+	})
+
+	t.Run("returns ReloadFunc error", func(t *testing.T) {
+		t.Parallel()
+
+		reloadErr := errors.New("reload failed")
+		r := graceful.RunnerType{ReloadFunc: func(ctx context.Context) error { return reloadErr }}
+		err := r.Reload(t.Context())
+		require.ErrorIs(t, err, reloadErr)
+	})
+}
+
+func TestMarkReady(t *testing.T) {
+	t.Parallel()
+
+	t.Run("does not panic when nothing is waiting", func(t *testing.T) {
+		t.Parallel()
+
+		require.NotPanics(t, func() {
+			graceful.MarkReady(t.Context())
+		})
+	})
+
+	t.Run("does not mark the parent of a runner which is not awaited as ready", func(t *testing.T) {
+		t.Parallel()
+
+		readyCh := make(chan struct{})
+		startedCh := make(chan struct{})
+		s := graceful.Stages{
+			graceful.Group{graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error {
+					graceful.MarkReady(ctx)
+					<-ctx.Done()
+					return nil
+				},
+				ReadyFunc: func(ctx context.Context) error {
+					<-readyCh
+					return nil
+				},
+			}},
+			graceful.RunnerType{StartFunc: func(ctx context.Context) error {
+				close(startedCh)
+				return nil
+			}},
+		}
+
+		ctx, cancel := context.WithCancel(t.Context())
+		errCh := make(chan error, 1)
+		go func() { errCh <- s.Start(ctx) }()
+
+		select {
+		case <-startedCh:
+			require.FailNow(t, "started before the group was ready")
+		case <-time.After(25 * time.Millisecond):
+		}
+		close(readyCh)
+		<-startedCh
+		cancel()
+		require.NoError(t, <-errCh)
+	})
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	t.Run("runs stages", func(t *testing.T) {
+		t.Parallel()
+
+		rec := &recorder{}
+		ctx, cancel := context.WithCancel(t.Context())
+		s := graceful.Stages{
+			graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error {
+					rec.record("a start")
+					return nil
+				},
+				StopFunc: func(ctx context.Context) error {
+					rec.record("a stop")
+					return nil
+				},
+			},
+			graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error {
+					rec.record("b start")
+					cancel()
+					return nil
+				},
+				StopFunc: func(ctx context.Context) error {
+					rec.record("b stop")
+					return nil
+				},
+			},
+		}
+
+		err := graceful.Run(ctx, s)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, []string{"a start", "b start", "b stop", "a stop"}, rec.Events())
+	})
+
+	t.Run("returns all errors when joining errors", func(t *testing.T) {
+		t.Parallel()
+
+		aErr, bErr, cErr := errors.New("a failed"), errors.New("b failed"), errors.New("c failed")
+		g := graceful.Group{
+			graceful.RunnerType{StartFunc: func(ctx context.Context) error { return aErr }},
+			graceful.Graph{
+				{Name: "b", Runner: graceful.RunnerType{StopFunc: func(ctx context.Context) error { return bErr }}},
+				{Name: "c", Runner: graceful.RunnerType{StopFunc: func(ctx context.Context) error { return cErr }}},
+			},
+		}
+
+		err := graceful.Run(t.Context(), g, graceful.WithJoinedErrors())
+		require.ErrorIs(t, err, aErr)
+		require.ErrorIs(t, err, bErr)
+		require.ErrorIs(t, err, cErr)
+
+		joined, ok := err.(interface{ Unwrap() []error })
+		require.True(t, ok)
+		require.ElementsMatch(t, []error{
+			&graceful.RunnerError{Name: "0", Op: "start", Err: aErr},
+			&graceful.RunnerError{Name: "1/b", Op: "stop", Err: bErr},
+			&graceful.RunnerError{Name: "1/c", Op: "stop", Err: cErr},
+		}, joined.Unwrap())
+	})
+
+	t.Run("returns errors of runners which fail together when joining errors", func(t *testing.T) {
+		t.Parallel()
+
+		aErr, bErr := errors.New("a failed"), errors.New("b failed")
+		failCh := make(chan struct{})
+		g := graceful.Group{
+			graceful.RunnerType{StartFunc: func(ctx context.Context) error {
+				<-failCh
+				return aErr
+			}},
+			graceful.RunnerType{StartFunc: func(ctx context.Context) error {
+				<-failCh
+				return bErr
+			}},
+		}
+		close(failCh)
+
+		err := graceful.Run(t.Context(), g, graceful.WithJoinedErrors())
+		require.ErrorIs(t, err, aErr)
+		require.ErrorIs(t, err, bErr)
+	})
+
+	t.Run("returns context error along with joined errors", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		stopErr := errors.New("stop failed")
+		g := graceful.Group{
+			graceful.RunnerType{StopFunc: func(ctx context.Context) error { return stopErr }},
+		}
+
+		err := graceful.Run(ctx, g, graceful.WithJoinedErrors())
+		require.ErrorIs(t, err, stopErr)
+		require.ErrorIs(t, err, context.Canceled)
+
+		var runnerErr *graceful.RunnerError
+		require.ErrorAs(t, err, &runnerErr)
+		require.Equal(t, "0", runnerErr.Name)
+	})
+
+	t.Run("returns only context error when joining errors and no runners fail", func(t *testing.T) {
+		t.Parallel()
+
+		g := graceful.Group{
+			graceful.RunnerType{StartFunc: func(ctx context.Context) error { return nil }},
+		}
+
+		ctx, cancel := context.WithCancel(t.Context())
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+		}()
+
+		err := graceful.Run(ctx, g, graceful.WithJoinedErrors())
+		require.ErrorIs(t, err, context.Canceled)
+		require.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), 1)
+	})
+}
+
+func TestRunnerError_Error(t *testing.T) {
+	t.Parallel()
+
+	t.Run("includes op, name, and error", func(t *testing.T) {
+		t.Parallel()
+
+		err := &graceful.RunnerError{Name: "1/api", Op: "stop", Err: errors.New("oh no")}
+		require.Equal(t, "stop 1/api: oh no", err.Error())
+	})
+
+	t.Run("omits empty name", func(t *testing.T) {
+		t.Parallel()
+
+		err := &graceful.RunnerError{Op: "start", Err: errors.New("oh no")}
+		require.Equal(t, "start: oh no", err.Error())
+	})
+}
+
+func TestNamed(t *testing.T) {
+	t.Parallel()
+
+	t.Run("names runners in errors", func(t *testing.T) {
+		t.Parallel()
+
+		startErr := errors.New("start failed")
+		g := graceful.Group{
+			graceful.Named("api", graceful.Group{
+				graceful.Named("http", graceful.RunnerType{
+					StartFunc: func(ctx context.Context) error { return startErr },
+				}),
+			}),
+		}
+
+		err := graceful.Run(t.Context(), g, graceful.WithJoinedErrors())
+		var runnerErr *graceful.RunnerError
+		require.ErrorAs(t, err, &runnerErr)
+		require.Equal(t, "api/http", runnerErr.Name)
+	})
+
+	t.Run("preserves readiness of wrapped runner", func(t *testing.T) {
+		t.Parallel()
+
+		rec := &recorder{}
+		readyCh := make(chan struct{})
+		s := graceful.Stages{
+			graceful.Named("db", graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error {
+					<-ctx.Done()
+					return nil
+				},
+				ReadyFunc: func(ctx context.Context) error {
+					<-readyCh
+					rec.record("db ready")
+					return nil
+				},
+			}),
+			graceful.RunnerType{StartFunc: func(ctx context.Context) error {
+				rec.record("api start")
+				return nil
+			}},
+		}
+
+		ctx, cancel := context.WithCancel(t.Context())
+		errCh := make(chan error)
+		go func() { errCh <- s.Start(ctx) }()
+
+		time.Sleep(10 * time.Millisecond)
+		require.Empty(t, rec.Events())
+		close(readyCh)
+		require.Eventually(t, func() bool { return len(rec.Events()) == 2 }, time.Second, time.Millisecond)
+		cancel()
+		require.NoError(t, <-errCh)
+		require.Equal(t, []string{"db ready", "api start"}, rec.Events())
+	})
+}
+
+func TestStopTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns an error when stop exceeds the timeout", func(t *testing.T) {
+		t.Parallel()
+
+		g := graceful.Group{
+			graceful.Named("db", graceful.StopTimeout(10*time.Millisecond, graceful.RunnerType{
+				StopFunc: func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				},
+			})),
+		}
+
+		err := g.Stop(t.Context())
+		var timeoutErr *graceful.StopTimeoutError
+		require.ErrorAs(t, err, &timeoutErr)
+		require.Equal(t, "db", timeoutErr.Name)
+		require.Equal(t, 10*time.Millisecond, timeoutErr.Allotted)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Equal(t, "runner db exceeded allotted stop time of 10ms: context deadline exceeded", err.Error())
+	})
+
+	t.Run("returns stop result when stop is within the timeout", func(t *testing.T) {
+		t.Parallel()
+
+		stopErr := errors.New("stop failed")
+		r := graceful.StopTimeout(time.Second, graceful.RunnerType{
+			StopFunc: func(ctx context.Context) error { return stopErr },
+		})
+
+		require.Equal(t, stopErr, r.Stop(t.Context()))
+	})
+
+	t.Run("does not affect start", func(t *testing.T) {
+		t.Parallel()
+
+		startErr := errors.New("start failed")
+		r := graceful.StopTimeout(time.Millisecond, graceful.RunnerType{
+			StartFunc: func(ctx context.Context) error { return startErr },
+		})
+
+		require.Equal(t, startErr, r.Start(t.Context()))
+	})
+}
+
+func TestWithStopBudget(t *testing.T) {
+	t.Parallel()
+
+	t.Run("allots each runner a share of the remaining stop time", func(t *testing.T) {
+		t.Parallel()
+
+		startErr := errors.New("start failed")
+		stoppedCh := make(chan string, 2)
+		fast := func(name string) graceful.Runner {
+			return graceful.Named(name, graceful.RunnerType{
+				StopFunc: func(ctx context.Context) error {
+					if err := ctx.Err(); err != nil {
+						return err
+					}
+					stoppedCh <- name
+					return nil
+				},
+			})
+		}
+		g := graceful.Group{
+			graceful.Named("slow", graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error { return startErr },
+				StopFunc: func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				},
+			}),
+			fast("a"),
+			fast("b"),
+		}
+
+		err := graceful.Run(t.Context(), g,
+			graceful.WithStopTimeout(90*time.Millisecond),
+			graceful.WithStopBudget(),
+			graceful.WithJoinedErrors(),
+		)
+		require.ErrorIs(t, err, startErr)
+
+		var timeoutErr *graceful.StopTimeoutError
+		require.ErrorAs(t, err, &timeoutErr)
+		require.Equal(t, "slow", timeoutErr.Name)
+		require.LessOrEqual(t, timeoutErr.Allotted, 30*time.Millisecond)
+
+		require.Equal(t, "a", <-stoppedCh)
+		require.Equal(t, "b", <-stoppedCh)
+	})
+}
+
+func TestWithForceSignals(t *testing.T) {
+	signal := func(t *testing.T) {
+		t.Helper()
+		p, err := os.FindProcess(syscall.Getpid())
+		require.NoError(t, err)
+		require.NoError(t, p.Signal(syscall.SIGHUP))
+	}
+
+	t.Run("forces shutdown when the stop signal is repeated", func(t *testing.T) {
+		stoppingCh := make(chan struct{})
+		r := graceful.RunnerType{
+			StartFunc: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			StopFunc: func(ctx context.Context) error {
+				<-ctx.Done()
+				return context.Cause(ctx)
+			},
+		}
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			signal(t)
+			<-stoppingCh
+			signal(t)
+		}()
+
+		err := graceful.Run(t.Context(), r,
+			graceful.WithStopSignals(syscall.SIGHUP),
+			graceful.WithForceSignals(syscall.SIGHUP),
+			graceful.WithStoppingCh(stoppingCh),
+		)
+		forcedErr := &graceful.ForcedShutdownError{}
+		require.ErrorAs(t, err, &forcedErr)
+		require.Equal(t, syscall.SIGHUP, forcedErr.Signal)
+		require.ErrorAs(t, forcedErr.Err, new(*graceful.ForcedShutdownError), "stop context cause")
+	})
+
+	t.Run("exits when stopping outlasts the force exit grace period", func(t *testing.T) {
+		stoppingCh := make(chan struct{})
+		unblockCh := make(chan struct{})
+		defer close(unblockCh)
+		r := graceful.RunnerType{
+			StartFunc: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			StopFunc: func(ctx context.Context) error {
+				<-unblockCh
+				return nil
+			},
+		}
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			signal(t)
+			<-stoppingCh
+			signal(t)
+		}()
+
+		exitCode := -1
+		err := graceful.Run(t.Context(), r,
+			graceful.WithStopSignals(syscall.SIGHUP),
+			graceful.WithForceSignals(syscall.SIGHUP),
+			graceful.WithForceExit(50*time.Millisecond),
+			graceful.WithStoppingCh(stoppingCh),
+			graceful.WithExit(func(code int) { exitCode = code }),
+		)
+		require.IsType(t, &graceful.ForcedShutdownError{}, err)
+		require.Equal(t, 1, exitCode)
+	})
+
+	t.Run("does not exit when stopping finishes within the force exit grace period", func(t *testing.T) {
+		stoppingCh := make(chan struct{})
+		r := graceful.RunnerType{
+			StartFunc: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			StopFunc: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+		}
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			signal(t)
+			<-stoppingCh
+			signal(t)
+		}()
+
+		exited := false
+		err := graceful.Run(t.Context(), r,
+			graceful.WithStopSignals(syscall.SIGHUP),
+			graceful.WithForceSignals(syscall.SIGHUP),
+			graceful.WithForceExit(time.Second),
+			graceful.WithStoppingCh(stoppingCh),
+			graceful.WithExit(func(code int) { exited = true }),
+		)
+		require.IsType(t, &graceful.ForcedShutdownError{}, err)
+		require.False(t, exited)
+	})
+}
+
+func TestForcedShutdownError_Error(t *testing.T) {
+	t.Parallel()
+
+	t.Run("includes the signal and underlying error", func(t *testing.T) {
+		t.Parallel()
+
+		err := &graceful.ForcedShutdownError{Signal: syscall.SIGINT, Err: errors.New("boom")}
+		require.Equal(t, "shutdown forced by signal interrupt: boom", err.Error())
+	})
+
+	t.Run("omits the underlying error when nil", func(t *testing.T) {
+		t.Parallel()
+
+		err := &graceful.ForcedShutdownError{Signal: syscall.SIGINT}
+		require.Equal(t, "shutdown forced by signal interrupt", err.Error())
+	})
+}
+
+func TestWithPreStopDelay(t *testing.T) {
+	t.Run("closes the stopping channel then waits before stopping", func(t *testing.T) {
+		startErr := errors.New("start failed")
+		stoppingCh := make(chan struct{})
+		stoppingAtCh := make(chan time.Time, 1)
+		go func() {
+			<-stoppingCh
+			stoppingAtCh <- time.Now()
+		}()
+		var stoppedAt time.Time
+		r := graceful.RunnerType{
+			StartFunc: func(ctx context.Context) error { return startErr },
+			StopFunc: func(ctx context.Context) error {
+				stoppedAt = time.Now()
+				return nil
+			},
+		}
+
+		err := graceful.Run(t.Context(), r,
+			graceful.WithStoppingCh(stoppingCh),
+			graceful.WithPreStopDelay(50*time.Millisecond),
+		)
+		require.ErrorIs(t, err, startErr)
+		require.GreaterOrEqual(t, stoppedAt.Sub(<-stoppingAtCh), 40*time.Millisecond)
+	})
+
+	t.Run("cuts the delay short when the stop signal is repeated", func(t *testing.T) {
+		stoppingCh := make(chan struct{})
+		r := graceful.RunnerType{
+			StartFunc: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+		}
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			p, err := os.FindProcess(syscall.Getpid())
+			require.NoError(t, err)
+			require.NoError(t, p.Signal(syscall.SIGHUP))
+			<-stoppingCh
+			require.NoError(t, p.Signal(syscall.SIGHUP))
+		}()
+
+		began := time.Now()
+		err := graceful.Run(t.Context(), r,
+			graceful.WithStopSignals(syscall.SIGHUP),
+			graceful.WithStoppingCh(stoppingCh),
+			graceful.WithPreStopDelay(time.Hour),
+		)
+		require.NoError(t, err)
+		require.Less(t, time.Since(began), time.Minute)
+	})
+}
+
+func TestPanicError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("recovers panics in start and stops every runner", func(t *testing.T) {
+		t.Parallel()
+
+		stoppedCh := make(chan struct{})
+		g := graceful.Group{
+			graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error { panic("oh no") },
+			},
+			graceful.RunnerType{
+				StopFunc: func(ctx context.Context) error {
+					close(stoppedCh)
+					return nil
+				},
+			},
+		}
+
+		err := graceful.Run(t.Context(), g)
+		panicErr := &graceful.PanicError{}
+		require.ErrorAs(t, err, &panicErr)
+		require.Equal(t, "oh no", panicErr.Value)
+		require.Contains(t, string(panicErr.Stack), "graceful_test.TestPanicError")
+		<-stoppedCh
+	})
+
+	t.Run("recovers panics in stop", func(t *testing.T) {
+		t.Parallel()
+
+		stopErr := errors.New("oh no")
+		r := graceful.RunnerType{
+			StartFunc: func(ctx context.Context) error { return errors.New("start failed") },
+			StopFunc:  func(ctx context.Context) error { panic(stopErr) },
+		}
+
+		err := graceful.Run(t.Context(), r, graceful.WithJoinedErrors())
+		require.ErrorIs(t, err, stopErr)
+		require.ErrorAs(t, err, new(*graceful.PanicError))
+	})
+
+	t.Run("formats the panic value", func(t *testing.T) {
+		t.Parallel()
+
+		err := &graceful.PanicError{Value: 42}
+		require.Equal(t, "panic: 42", err.Error())
+		require.NoError(t, err.Unwrap())
+	})
+}
+
+func TestWithStartTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stops when the tree does not start in time", func(t *testing.T) {
+		t.Parallel()
+
+		stoppedCh := make(chan struct{})
+		g := graceful.Group{
+			graceful.Named("fast", graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error {
+					<-ctx.Done()
+					return nil
+				},
+			}),
+			graceful.Named("slow", graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error {
+					<-ctx.Done()
+					return nil
+				},
+				ReadyFunc: func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				},
+				StopFunc: func(ctx context.Context) error {
+					close(stoppedCh)
+					return nil
+				},
+			}),
+		}
+
+		ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+		defer cancel()
+		err := graceful.Run(ctx, g, graceful.WithStartTimeout(20*time.Millisecond))
+		timeoutErr := &graceful.StartTimeoutError{}
+		require.ErrorAs(t, err, &timeoutErr)
+		require.Equal(t, 20*time.Millisecond, timeoutErr.Timeout)
+		require.Equal(t, []string{"slow"}, timeoutErr.Pending)
+		<-stoppedCh
+	})
+
+	t.Run("does not stop when the tree starts in time", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		defer cancel()
+		r := graceful.AwaitReady(graceful.RunnerType{
+			StartFunc: func(ctx context.Context) error {
+				graceful.MarkReady(ctx)
+				<-ctx.Done()
+				return nil
+			},
+		})
+
+		err := graceful.Run(ctx, graceful.Group{r}, graceful.WithStartTimeout(10*time.Millisecond))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("does not stop for runners which do not opt into readiness", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		defer cancel()
+		r := graceful.RunnerType{
+			StartFunc: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			StopFunc: func(ctx context.Context) error { return nil },
+		}
+
+		err := graceful.Run(ctx, graceful.Group{r}, graceful.WithStartTimeout(10*time.Millisecond))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestStartTimeoutError_Error(t *testing.T) {
+	t.Parallel()
+
+	t.Run("includes the pending runners", func(t *testing.T) {
+		t.Parallel()
+
+		err := &graceful.StartTimeoutError{Timeout: time.Second, Pending: []string{"a", "b/c"}}
+		require.Equal(t, "not started within 1s: waiting on a, b/c", err.Error())
+	})
+
+	t.Run("omits the pending runners when there are none", func(t *testing.T) {
+		t.Parallel()
+
+		err := &graceful.StartTimeoutError{Timeout: time.Second}
+		require.Equal(t, "not started within 1s", err.Error())
+	})
+}
+
+func TestShutdownReason(t *testing.T) {
+	// blocking returns a runner which blocks until stopped, sending the cause
+	// of its start context on causeCh once it is canceled.
+	blocking := func(causeCh chan<- error) graceful.Runner {
+		return graceful.RunnerType{
+			StartFunc: func(ctx context.Context) error {
+				<-ctx.Done()
+				causeCh <- context.Cause(ctx)
+				return nil
+			},
+		}
+	}
+
+	t.Run("cancels start contexts with the signal which was received", func(t *testing.T) {
+		causeCh := make(chan error, 1)
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			p, err := os.FindProcess(syscall.Getpid())
+			require.NoError(t, err)
+			require.NoError(t, p.Signal(syscall.SIGHUP))
+		}()
+
+		err := graceful.Run(t.Context(), blocking(causeCh), graceful.WithStopSignals(syscall.SIGHUP))
+		require.NoError(t, err)
+		reason := &graceful.ShutdownReason{}
+		require.ErrorAs(t, <-causeCh, &reason)
+		require.Equal(t, syscall.SIGHUP, reason.Signal)
+	})
+
+	t.Run("stops when a runner fails while its siblings are running", func(t *testing.T) {
+		startErr := errors.New("start failed")
+		startedCh, causeCh := make(chan struct{}), make(chan error, 1)
+		g := graceful.Group{
+			graceful.Named("api", graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error {
+					close(startedCh)
+					<-ctx.Done()
+					causeCh <- context.Cause(ctx)
+					return nil
+				},
+			}),
+			graceful.Named("db", graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error {
+					<-startedCh
+					return startErr
+				},
+			}),
+		}
+
+		ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+		defer cancel()
+		err := graceful.Run(ctx, g)
+		reason := &graceful.ShutdownReason{}
+		require.ErrorAs(t, err, &reason)
+		require.Equal(t, "db", reason.Runner)
+		require.ErrorIs(t, err, startErr)
+		require.NoError(t, ctx.Err())
+		require.ErrorAs(t, <-causeCh, &reason)
+		require.Equal(t, "db", reason.Runner)
+	})
+
+	t.Run("cancels start contexts with the cause of the parent context", func(t *testing.T) {
+		cause := errors.New("shutting down")
+		ctx, cancel := context.WithCancelCause(t.Context())
+		causeCh := make(chan error, 1)
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cancel(cause)
+		}()
+
+		err := graceful.Run(ctx, blocking(causeCh))
+		reason := &graceful.ShutdownReason{}
+		require.ErrorAs(t, err, &reason)
+		require.True(t, reason.Canceled)
+		require.ErrorIs(t, err, cause)
+		require.ErrorAs(t, <-causeCh, &reason)
+		require.True(t, reason.Canceled)
+	})
+
+	t.Run("does not stop when a member of a dynamic group fails", func(t *testing.T) {
+		exitCh := make(chan struct{}, 1)
+		g := &graceful.DynamicGroup{OnExit: func(string, error) { exitCh <- struct{}{} }}
+		require.NoError(t, g.Add("a", graceful.RunnerType{
+			StartFunc: func(ctx context.Context) error { return errors.New("start failed") },
+		}))
+
+		ctx, cancel := context.WithCancel(t.Context())
+		errCh := make(chan error, 1)
+		go func() { errCh <- graceful.Run(ctx, graceful.Group{g}) }()
+		<-exitCh
+		time.Sleep(10 * time.Millisecond)
+		require.Empty(t, errCh)
+
+		cancel()
+		require.ErrorIs(t, <-errCh, context.Canceled)
+	})
+}
+
+func TestShutdownReason_Error(t *testing.T) {
+	t.Parallel()
+
+	err := errors.New("oh no")
+	for want, reason := range map[string]*graceful.ShutdownReason{
+		"received signal hangup":         {Signal: syscall.SIGHUP},
+		"completed":                      {Completed: true},
+		"parent context canceled: oh no": {Canceled: true, Err: err},
+		"runner api/http failed: oh no":  {Runner: "api/http", Err: err},
+		"oh no":                          {Err: err},
+	} {
+		require.Equal(t, want, reason.Error())
+	}
+}
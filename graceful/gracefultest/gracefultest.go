@@ -0,0 +1,312 @@
+// Package gracefultest provides utilities for testing implementations of
+// [graceful.Runner].
+package gracefultest
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wafer-bw/go-toolbox/graceful"
+)
+
+// Option configures [Check].
+type Option func(*config)
+
+type config struct {
+	timeout time.Duration
+	cycles  int
+	advance time.Duration
+}
+
+// WithTimeout sets how long [Check] waits for each call to [graceful.Runner]
+// methods to return once they are required to. The default is 1 second.
+func WithTimeout(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.timeout = d
+	}
+}
+
+// WithCycles sets how many times [Check] runs through each scenario. The
+// default is 1.
+func WithCycles(n int) Option {
+	return func(cfg *config) {
+		cfg.cycles = n
+	}
+}
+
+// WithAdvance makes [Check] advance the [Clock] passed to the runner it starts
+// then stops by d before stopping it. The clock is advanced once the runner is
+// waiting on it or the timeout passed via [WithTimeout] elapses.
+func WithAdvance(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.advance = d
+	}
+}
+
+// Check runs runners created via newRunner through start/stop cycles, failing
+// t if any of them violate the contract described by [graceful.Runner]:
+//   - Start must return once Stop has returned.
+//   - Start must return once its context is canceled.
+//   - Start must not panic if Stop was called first.
+//   - Stop must return once its context is canceled.
+//   - Stop must not panic if Start was not called.
+//
+// Each scenario uses a new runner which is passed a new [Clock] (see
+// [WithAdvance]). Once all scenarios have run, t also fails if any goroutines
+// started during them are still running. Because goroutines are tracked
+// process wide, Check must not be used from parallel tests.
+func Check(t testing.TB, newRunner func(clock *Clock) graceful.Runner, opts ...Option) {
+	t.Helper()
+
+	cfg := &config{timeout: time.Second, cycles: 1}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(cfg)
+	}
+
+	before := goroutines()
+	for range max(cfg.cycles, 1) {
+		c := &checker{t: t, cfg: cfg}
+		clock := NewClock()
+		c.startThenStop(newRunner(clock), clock)
+		c.stopBeforeStart(newRunner(NewClock()))
+		c.startCanceled(newRunner(NewClock()))
+		c.stopCanceled(newRunner(NewClock()))
+	}
+
+	if leaked := leakedSince(before, cfg.timeout); len(leaked) != 0 {
+		t.Errorf("%d goroutines leaked:\n\n%s", len(leaked), strings.Join(leaked, "\n\n"))
+	}
+}
+
+type checker struct {
+	t   testing.TB
+	cfg *config
+}
+
+// call fn in a new goroutine, returning a channel which receives once it
+// returns. A panic from fn fails the test.
+func (c *checker) call(method string, fn func() error) <-chan error {
+	doneCh := make(chan error, 1)
+	go func() {
+		defer func() {
+			if v := recover(); v != nil {
+				c.t.Errorf("%s panicked: %v", method, v)
+				doneCh <- nil
+			}
+		}()
+		doneCh <- fn()
+	}()
+
+	return doneCh
+}
+
+// wait for doneCh to receive, failing the test with the provided message
+// unless it is empty if it does not within the configured timeout.
+func (c *checker) wait(doneCh <-chan error, format string, args ...any) bool {
+	timer := time.NewTimer(c.cfg.timeout)
+	defer timer.Stop()
+
+	select {
+	case <-doneCh:
+		return true
+	case <-timer.C:
+		if format != "" {
+			c.t.Errorf(format, args...)
+		}
+		return false
+	}
+}
+
+func (c *checker) startThenStop(r graceful.Runner, clock *Clock) {
+	startCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	startCh := c.call("Start", func() error { return r.Start(startCtx) })
+	if c.cfg.advance > 0 {
+		deadline := time.Now().Add(c.cfg.timeout)
+		for clock.Waiters() == 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		clock.Advance(c.cfg.advance)
+	}
+
+	if !c.stop(r, "after Start was called") {
+		return
+	}
+	if !c.wait(startCh, "Start did not return after Stop returned") {
+		cancel()
+		c.wait(startCh, "Start did not return after its context was canceled")
+	}
+}
+
+func (c *checker) stopBeforeStart(r graceful.Runner) {
+	if !c.stop(r, "when Start was not called") {
+		return
+	}
+
+	startCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	startCh := c.call("Start", func() error { return r.Start(startCtx) })
+	if !c.wait(startCh, "") {
+		cancel()
+		c.wait(startCh, "Start did not return after its context was canceled when Stop was called first")
+	}
+}
+
+func (c *checker) startCanceled(r graceful.Runner) {
+	startCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c.wait(c.call("Start", func() error { return r.Start(startCtx) }), "Start did not return after its context was canceled")
+
+	c.stop(r, "after Start returned")
+}
+
+// stop r, failing the test if it does not return once its context is
+// canceled. when describes the circumstances of the call.
+func (c *checker) stop(r graceful.Runner, when string) bool {
+	stopCtx, stopCancel := context.WithCancel(context.Background())
+	defer stopCancel()
+	stopCh := c.call("Stop", func() error { return r.Stop(stopCtx) })
+	if c.wait(stopCh, "") {
+		return true
+	}
+	stopCancel()
+
+	return c.wait(stopCh, "Stop did not return after its context was canceled %s", when)
+}
+
+func (c *checker) stopCanceled(r graceful.Runner) {
+	startCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	startCh := c.call("Start", func() error { return r.Start(startCtx) })
+
+	stopCtx, stopCancel := context.WithCancel(context.Background())
+	stopCancel()
+	c.wait(c.call("Stop", func() error { return r.Stop(stopCtx) }), "Stop did not return after its context was canceled")
+
+	cancel()
+	c.wait(startCh, "Start did not return after its context was canceled")
+}
+
+// goroutines returns the stacks of all running goroutines keyed by their ID.
+func goroutines() map[string]string {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	stacks := map[string]string{}
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		header, _, _ := strings.Cut(string(stack), " [")
+		stacks[header] = string(stack)
+	}
+
+	return stacks
+}
+
+// leakedSince returns the stacks of the goroutines which were not running at
+// the time of before and are still running after waiting up to timeout for
+// them to exit.
+func leakedSince(before map[string]string, timeout time.Duration) []string {
+	deadline := time.Now().Add(timeout)
+	for {
+		var leaked []string
+		for id, stack := range goroutines() {
+			if _, ok := before[id]; !ok {
+				leaked = append(leaked, stack)
+			}
+		}
+		if len(leaked) == 0 || time.Now().After(deadline) {
+			slices.Sort(leaked)
+			return leaked
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Clock is a fake clock whose time only moves when it is advanced, allowing
+// runners which depend on the passage of time to be tested deterministically.
+// The zero value is not ready to use, see [NewClock].
+type Clock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []waiter
+}
+
+type waiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// NewClock creates a new instance of [Clock] set to an arbitrary fixed time.
+func NewClock() *Clock {
+	return &Clock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+// Now returns the current time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// After returns a channel which receives the time of the clock once it has
+// been advanced by at least d.
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, waiter{at: c.now.Add(d), ch: ch})
+
+	return ch
+}
+
+// Since returns the time elapsed on the clock since t.
+func (c *Clock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// Waiters returns the number of channels returned by [Clock.After] which are
+// not yet due.
+func (c *Clock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.waiters)
+}
+
+// Advance the clock by d, notifying any channels returned by [Clock.After]
+// which are now due.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	c.waiters = slices.DeleteFunc(c.waiters, func(w waiter) bool {
+		if w.at.After(c.now) {
+			return false
+		}
+		w.ch <- c.now
+		return true
+	})
+}
@@ -0,0 +1,161 @@
+package gracefultest_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wafer-bw/go-toolbox/graceful"
+	"github.com/wafer-bw/go-toolbox/graceful/gracefultest"
+)
+
+// recorder records failures rather than failing the test.
+type recorder struct {
+	testing.TB
+
+	mu   sync.Mutex
+	errs []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) failures() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Join(r.errs, "\n")
+}
+
+func TestCheck(t *testing.T) {
+	t.Run("passes runners which honor the contract", func(t *testing.T) {
+		rec := &recorder{TB: t}
+		gracefultest.Check(rec, func(clock *gracefultest.Clock) graceful.Runner {
+			return &graceful.Ticker{
+				Interval: time.Millisecond,
+				Task:     func(ctx context.Context) error { return nil },
+			}
+		}, gracefultest.WithCycles(3))
+		gracefultest.Check(rec, func(clock *gracefultest.Clock) graceful.Runner {
+			return &graceful.Drain{}
+		})
+		require.Empty(t, rec.failures())
+	})
+
+	t.Run("advances the clock before stopping", func(t *testing.T) {
+		rec := &recorder{TB: t}
+		advanced := make(chan struct{}, 1)
+		gracefultest.Check(rec, func(clock *gracefultest.Clock) graceful.Runner {
+			return graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error {
+					select {
+					case <-clock.After(time.Minute):
+						advanced <- struct{}{}
+					case <-ctx.Done():
+					}
+					return nil
+				},
+			}
+		}, gracefultest.WithAdvance(time.Minute), gracefultest.WithTimeout(50*time.Millisecond))
+		require.Empty(t, rec.failures())
+		<-advanced
+	})
+
+	t.Run("reports a start which does not return after stop", func(t *testing.T) {
+		rec := &recorder{TB: t}
+		gracefultest.Check(rec, func(clock *gracefultest.Clock) graceful.Runner {
+			return graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error {
+					<-ctx.Done()
+					return nil
+				},
+			}
+		}, gracefultest.WithTimeout(20*time.Millisecond))
+		require.Contains(t, rec.failures(), "Start did not return after Stop returned")
+	})
+
+	t.Run("reports a stop which ignores its context", func(t *testing.T) {
+		rec := &recorder{TB: t}
+		unblockCh := make(chan struct{})
+		gracefultest.Check(rec, func(clock *gracefultest.Clock) graceful.Runner {
+			return graceful.RunnerType{
+				StopFunc: func(ctx context.Context) error {
+					<-unblockCh
+					return nil
+				},
+			}
+		}, gracefultest.WithTimeout(20*time.Millisecond))
+		close(unblockCh)
+		require.Contains(t, rec.failures(), "Stop did not return after its context was canceled")
+	})
+
+	t.Run("reports a stop which panics when start was not called", func(t *testing.T) {
+		rec := &recorder{TB: t}
+		gracefultest.Check(rec, func(clock *gracefultest.Clock) graceful.Runner {
+			started := &atomic.Bool{}
+			return graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error {
+					started.Store(true)
+					return nil
+				},
+				StopFunc: func(ctx context.Context) error {
+					if !started.Load() {
+						panic("not started")
+					}
+					return nil
+				},
+			}
+		}, gracefultest.WithTimeout(20*time.Millisecond))
+		require.Contains(t, rec.failures(), "Stop panicked: not started")
+	})
+
+	t.Run("reports leaked goroutines", func(t *testing.T) {
+		rec := &recorder{TB: t}
+		unblockCh := make(chan struct{})
+		gracefultest.Check(rec, func(clock *gracefultest.Clock) graceful.Runner {
+			return graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error {
+					go func() { <-unblockCh }()
+					return nil
+				},
+			}
+		}, gracefultest.WithTimeout(20*time.Millisecond))
+		close(unblockCh)
+		require.Contains(t, rec.failures(), "goroutines leaked")
+	})
+}
+
+func TestClock(t *testing.T) {
+	t.Run("only moves when advanced", func(t *testing.T) {
+		clock := gracefultest.NewClock()
+		start := clock.Now()
+		afterCh := clock.After(time.Second)
+
+		require.Equal(t, 1, clock.Waiters())
+		clock.Advance(500 * time.Millisecond)
+		require.Equal(t, 500*time.Millisecond, clock.Since(start))
+		select {
+		case <-afterCh:
+			t.Fatal("after fired early")
+		default:
+		}
+
+		clock.Advance(500 * time.Millisecond)
+		require.Equal(t, start.Add(time.Second), <-afterCh)
+		require.Zero(t, clock.Waiters())
+	})
+
+	t.Run("fires immediately for non-positive durations", func(t *testing.T) {
+		clock := gracefultest.NewClock()
+		require.Equal(t, clock.Now(), <-clock.After(0))
+	})
+}
@@ -0,0 +1,251 @@
+package graceful
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Node is a named [Runner] within a [Graph] along with the names of the other
+// nodes it depends on.
+type Node struct {
+	Name      string
+	Runner    Runner
+	DependsOn []string
+}
+
+// Graph of [Node] forming a directed acyclic graph
+// (https://en.wikipedia.org/wiki/Directed_acyclic_graph).
+//
+// Each [Node] is started as soon as all of the nodes it depends on are ready
+// (see [Readier]) and is stopped as soon as all of the nodes which depend on it
+// have stopped, allowing for maximum parallelism in both directions.
+//
+// Graph satisfies [Runner] and thus it can be nested within a [Group] or
+// itself to create a tree. Graph is ready once all of its nodes are ready.
+type Graph []Node
+
+// Validate the graph, returning a [DuplicateNodeError],
+// [MissingDependencyError], or [CycleError] if it is not a valid directed
+// acyclic graph.
+func (g Graph) Validate() error {
+	nodes := make(map[string]Node, len(g))
+	for _, n := range g {
+		if _, ok := nodes[n.Name]; ok {
+			return &DuplicateNodeError{Name: n.Name}
+		}
+		nodes[n.Name] = n
+	}
+
+	for _, n := range g {
+		for _, dep := range n.DependsOn {
+			if _, ok := nodes[dep]; !ok {
+				return &MissingDependencyError{Node: n.Name, Dependency: dep}
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make(map[string]int, len(g))
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch marks[name] {
+		case visited:
+			return nil
+		case visiting:
+			for i, p := range path {
+				if p == name {
+					return &CycleError{Path: append(append([]string{}, path[i:]...), name)}
+				}
+			}
+		}
+
+		marks[name] = visiting
+		path = append(path, name)
+		for _, dep := range nodes[name].DependsOn {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		marks[name] = visited
+
+		return nil
+	}
+	for _, n := range g {
+		if err := visit(n.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Start each [Node] once all of the nodes it depends on are ready. Blocks
+// until all started [Runner.Start] have returned normally, then returns the
+// first non-nil error (if any) from them.
+//
+// If a [Node] fails to become ready, the nodes which depend on it are not
+// started and its error is returned immediately. The nodes which were already
+// started keep running until they are stopped.
+//
+// If the graph is invalid (see [Graph.Validate]), nothing is started and the
+// validation error is returned.
+func (g Graph) Start(ctx context.Context) error {
+	if err := g.Validate(); err != nil {
+		report(ctx, "start", err)
+		return err
+	}
+
+	type result struct {
+		done chan struct{}
+		err  error
+	}
+	results := make(map[string]*result, len(g))
+	for _, n := range g {
+		results[n.Name] = &result{done: make(chan struct{})}
+	}
+
+	readyErrCh := make(chan error, 1)
+	eg := new(errgroup.Group)
+	for _, n := range g {
+		res := results[n.Name]
+		eg.Go(func() error {
+			for _, dep := range n.DependsOn {
+				depRes := results[dep]
+				<-depRes.done
+				if depRes.err != nil {
+					res.err = depRes.err
+					close(res.done)
+					return nil
+				}
+			}
+
+			if n.Runner == nil {
+				close(res.done)
+				return nil
+			}
+
+			start, ready := withReady(ctx, n.Name, n.Runner)
+			go func() {
+				if res.err = <-ready; res.err != nil {
+					select {
+					case readyErrCh <- res.err:
+					default:
+					}
+				}
+				close(res.done)
+			}()
+
+			return start()
+		})
+	}
+
+	go func() {
+		for _, res := range results {
+			if <-res.done; res.err != nil {
+				return
+			}
+		}
+		MarkReady(ctx)
+	}()
+
+	waitCh := make(chan error, 1)
+	go func() {
+		err := eg.Wait()
+		for _, res := range results {
+			<-res.done
+		}
+		waitCh <- err
+	}()
+
+	select {
+	case err := <-readyErrCh:
+		return err
+	case err := <-waitCh:
+		select {
+		case readyErr := <-readyErrCh:
+			return readyErr
+		default:
+			return err
+		}
+	}
+}
+
+// Stop each [Node] once all of the nodes which depend on it have stopped.
+// Blocks until all [Runner.Stop] have returned normally, then returns the
+// first non-nil error (if any) from them.
+//
+// If the graph is invalid (see [Graph.Validate]), nothing is stopped and the
+// validation error is returned.
+func (g Graph) Stop(ctx context.Context) error {
+	if err := g.Validate(); err != nil {
+		report(ctx, "stop", err)
+		return err
+	}
+
+	stopped := make(map[string]chan struct{}, len(g))
+	dependents := make(map[string][]string, len(g))
+	for _, n := range g {
+		stopped[n.Name] = make(chan struct{})
+		for _, dep := range n.DependsOn {
+			dependents[dep] = append(dependents[dep], n.Name)
+		}
+	}
+
+	eg := new(errgroup.Group)
+	for _, n := range g {
+		eg.Go(func() error {
+			defer close(stopped[n.Name])
+			for _, dependent := range dependents[n.Name] {
+				<-stopped[dependent]
+			}
+
+			if n.Runner == nil {
+				return nil
+			}
+			return stopRunner(ctx, n.Name, n.Runner, 0)
+		})
+	}
+
+	return eg.Wait()
+}
+
+// DuplicateNodeError occurs when more than one [Node] in a [Graph] has the
+// same name.
+type DuplicateNodeError struct {
+	Name string
+}
+
+func (e *DuplicateNodeError) Error() string {
+	return fmt.Sprintf("duplicate node %q", e.Name)
+}
+
+// MissingDependencyError occurs when a [Node] in a [Graph] depends on a node
+// which is not in the graph.
+type MissingDependencyError struct {
+	Node       string
+	Dependency string
+}
+
+func (e *MissingDependencyError) Error() string {
+	return fmt.Sprintf("node %q depends on missing node %q", e.Node, e.Dependency)
+}
+
+// CycleError occurs when the nodes of a [Graph] depend on each other in a
+// cycle. Path lists the names of the nodes forming the cycle, starting and
+// ending with the same node.
+type CycleError struct {
+	Path []string
+}
+
+func (e *CycleError) Error() string {
+	return "dependency cycle: " + strings.Join(e.Path, " -> ")
+}
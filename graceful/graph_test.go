@@ -0,0 +1,257 @@
+package graceful_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wafer-bw/go-toolbox/graceful"
+)
+
+func TestGraph_Validate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns nil for a valid graph", func(t *testing.T) {
+		t.Parallel()
+
+		g := graceful.Graph{
+			{Name: "db"},
+			{Name: "cache"},
+			{Name: "api", DependsOn: []string{"db", "cache"}},
+			{Name: "worker", DependsOn: []string{"db"}},
+		}
+		require.NoError(t, g.Validate())
+	})
+
+	t.Run("returns an error for duplicate nodes", func(t *testing.T) {
+		t.Parallel()
+
+		g := graceful.Graph{{Name: "db"}, {Name: "db"}}
+		err := g.Validate()
+		require.Equal(t, &graceful.DuplicateNodeError{Name: "db"}, err)
+		require.Equal(t, `duplicate node "db"`, err.Error())
+	})
+
+	t.Run("returns an error for missing dependencies", func(t *testing.T) {
+		t.Parallel()
+
+		g := graceful.Graph{{Name: "api", DependsOn: []string{"db"}}}
+		err := g.Validate()
+		require.Equal(t, &graceful.MissingDependencyError{Node: "api", Dependency: "db"}, err)
+		require.Equal(t, `node "api" depends on missing node "db"`, err.Error())
+	})
+
+	t.Run("returns an error for cycles", func(t *testing.T) {
+		t.Parallel()
+
+		g := graceful.Graph{
+			{Name: "a", DependsOn: []string{"b"}},
+			{Name: "b", DependsOn: []string{"c"}},
+			{Name: "c", DependsOn: []string{"a"}},
+		}
+		err := g.Validate()
+		require.Equal(t, &graceful.CycleError{Path: []string{"a", "b", "c", "a"}}, err)
+		require.Equal(t, "dependency cycle: a -> b -> c -> a", err.Error())
+	})
+
+	t.Run("returns an error for self dependencies", func(t *testing.T) {
+		t.Parallel()
+
+		g := graceful.Graph{{Name: "a", DependsOn: []string{"a"}}}
+		err := g.Validate()
+		require.Equal(t, &graceful.CycleError{Path: []string{"a", "a"}}, err)
+	})
+}
+
+func TestGraph_Start(t *testing.T) {
+	t.Parallel()
+
+	t.Run("starts nodes once their dependencies are ready", func(t *testing.T) {
+		t.Parallel()
+
+		rec := &recorder{}
+		node := func(name string, delay time.Duration) graceful.Runner {
+			return graceful.AwaitReady(graceful.RunnerType{StartFunc: func(ctx context.Context) error {
+				rec.record(name + " start")
+				time.Sleep(delay)
+				rec.record(name + " ready")
+				graceful.MarkReady(ctx)
+				<-ctx.Done()
+				return nil
+			}})
+		}
+		g := graceful.Graph{
+			{Name: "api", Runner: node("api", 0), DependsOn: []string{"db", "cache"}},
+			{Name: "db", Runner: node("db", 50*time.Millisecond)},
+			{Name: "cache", Runner: node("cache", 0)},
+		}
+
+		ctx, cancel := context.WithCancel(t.Context())
+		errCh := make(chan error)
+		go func() { errCh <- g.Start(ctx) }()
+
+		require.Eventually(t, func() bool { return len(rec.Events()) == 6 }, time.Second, time.Millisecond)
+		cancel()
+		require.NoError(t, <-errCh)
+
+		events := rec.Events()
+		require.ElementsMatch(t, []string{"db start", "cache start", "cache ready"}, events[:3])
+		require.Equal(t, []string{"db ready", "api start", "api ready"}, events[3:])
+	})
+
+	t.Run("does not start dependents of a failed node", func(t *testing.T) {
+		t.Parallel()
+
+		startErr := errors.New("start failed")
+		started := false
+		g := graceful.Graph{
+			{Name: "db", Runner: graceful.AwaitReady(graceful.RunnerType{StartFunc: func(ctx context.Context) error {
+				return startErr
+			}})},
+			{Name: "api", DependsOn: []string{"db"}, Runner: graceful.RunnerType{StartFunc: func(ctx context.Context) error {
+				started = true
+				return nil
+			}}},
+		}
+
+		err := g.Start(t.Context())
+		require.Equal(t, startErr, err)
+		require.False(t, started)
+	})
+
+	t.Run("returns ready error without waiting for started nodes", func(t *testing.T) {
+		t.Parallel()
+
+		readyErr := errors.New("not ready")
+		started := false
+		g := graceful.Graph{
+			{Name: "cache", Runner: graceful.RunnerType{StartFunc: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			}}},
+			{Name: "db", Runner: graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error {
+					<-ctx.Done()
+					return nil
+				},
+				ReadyFunc: func(ctx context.Context) error { return readyErr },
+			}},
+			{Name: "api", DependsOn: []string{"db"}, Runner: graceful.RunnerType{StartFunc: func(ctx context.Context) error {
+				started = true
+				return nil
+			}}},
+		}
+
+		err := g.Start(t.Context())
+		require.Equal(t, readyErr, err)
+		require.False(t, started)
+	})
+
+	t.Run("stops the tree when run and a node fails to become ready", func(t *testing.T) {
+		t.Parallel()
+
+		readyErr := errors.New("not ready")
+		g := graceful.Graph{
+			{Name: "db", Runner: graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error {
+					<-ctx.Done()
+					return nil
+				},
+				ReadyFunc: func(ctx context.Context) error { return readyErr },
+			}},
+			{Name: "api", DependsOn: []string{"db"}, Runner: graceful.RunnerType{}},
+		}
+
+		ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+		defer cancel()
+
+		err := graceful.Run(ctx, g)
+		reason := &graceful.ShutdownReason{}
+		require.ErrorAs(t, err, &reason)
+		require.Equal(t, "db", reason.Runner)
+		require.ErrorIs(t, err, readyErr)
+		require.NoError(t, ctx.Err())
+	})
+
+	t.Run("returns validation error without starting anything", func(t *testing.T) {
+		t.Parallel()
+
+		started := false
+		g := graceful.Graph{
+			{Name: "a", Runner: graceful.RunnerType{StartFunc: func(ctx context.Context) error {
+				started = true
+				return nil
+			}}},
+			{Name: "b", DependsOn: []string{"c"}},
+		}
+
+		err := g.Start(t.Context())
+		require.IsType(t, &graceful.MissingDependencyError{}, err)
+		require.False(t, started)
+	})
+
+	t.Run("does not panic when runners are nil", func(t *testing.T) {
+		t.Parallel()
+
+		g := graceful.Graph{{Name: "a"}, {Name: "b", DependsOn: []string{"a"}}}
+		require.NotPanics(t, func() {
+			err := g.Start(t.Context())
+			require.NoError(t, err)
+		})
+	})
+}
+
+func TestGraph_Stop(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stops nodes after their dependents", func(t *testing.T) {
+		t.Parallel()
+
+		rec := &recorder{}
+		node := func(name string) graceful.Runner {
+			return graceful.RunnerType{StopFunc: func(ctx context.Context) error {
+				rec.record(name)
+				return nil
+			}}
+		}
+		g := graceful.Graph{
+			{Name: "db", Runner: node("db")},
+			{Name: "api", Runner: node("api"), DependsOn: []string{"db", "cache"}},
+			{Name: "cache", Runner: node("cache"), DependsOn: []string{"db"}},
+		}
+
+		err := g.Stop(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, []string{"api", "cache", "db"}, rec.Events())
+	})
+
+	t.Run("stops all nodes and returns first stop error", func(t *testing.T) {
+		t.Parallel()
+
+		stopErr := errors.New("stop failed")
+		stopped := false
+		g := graceful.Graph{
+			{Name: "db", Runner: graceful.RunnerType{StopFunc: func(ctx context.Context) error {
+				stopped = true
+				return nil
+			}}},
+			{Name: "api", DependsOn: []string{"db"}, Runner: graceful.RunnerType{StopFunc: func(ctx context.Context) error {
+				return stopErr
+			}}},
+		}
+
+		err := g.Stop(t.Context())
+		require.Equal(t, stopErr, err)
+		require.True(t, stopped)
+	})
+
+	t.Run("returns validation error without stopping anything", func(t *testing.T) {
+		t.Parallel()
+
+		g := graceful.Graph{{Name: "a", DependsOn: []string{"a"}}}
+		err := g.Stop(t.Context())
+		require.IsType(t, &graceful.CycleError{}, err)
+	})
+}
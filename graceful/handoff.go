@@ -0,0 +1,340 @@
+package graceful
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	listenersEnv = "GRACEFUL_LISTENERS"
+	readyFDEnv   = "GRACEFUL_READY_FD"
+)
+
+// Listeners is a registry of listening sockets which can be handed off to a
+// re-executed child process, allowing the binary to be restarted without
+// refusing any connections.
+//
+// Runners obtain their listeners via [Listeners.Listen]. In a child started via
+// [Listeners.Handoff], Listen adopts the listener inherited from the parent for
+// the same network & address rather than creating a new one. Once the child is
+// ready it calls [Listeners.Ready], after which the parent stops, draining its
+// connections. See [WithHandoff] to coordinate this with [Run].
+//
+// Handing off is only supported on unix platforms. The zero value is ready to
+// use and Listeners must not be copied after first use.
+type Listeners struct {
+	mu        sync.Mutex
+	once      sync.Once
+	inherited map[string]*os.File
+	readyFile *os.File
+	keys      []string
+	listeners map[string]net.Listener
+	command   func() *exec.Cmd
+}
+
+// inherit parses the listeners inherited from the parent (if any) from the
+// environment, then removes them from it so that they are not inherited by
+// processes spawned by this one. It must be called with mu held.
+func (l *Listeners) inherit() {
+	l.once.Do(func() {
+		l.inherited = map[string]*os.File{}
+		l.listeners = map[string]net.Listener{}
+		if v := os.Getenv(listenersEnv); v != "" {
+			for i, key := range strings.Split(v, ",") {
+				l.inherited[key] = os.NewFile(uintptr(3+i), key)
+			}
+		}
+		if fd, err := strconv.Atoi(os.Getenv(readyFDEnv)); err == nil {
+			l.readyFile = os.NewFile(uintptr(fd), "ready")
+		}
+		_ = os.Unsetenv(listenersEnv)
+		_ = os.Unsetenv(readyFDEnv)
+	})
+}
+
+// Listen announces on the network address, see [net.Listen]. If the process was
+// started via [Listeners.Handoff] and the parent was listening on the same
+// network & address, its listener is adopted instead. The listener is
+// registered so that it is handed off by [Listeners.Handoff] until it is
+// closed.
+func (l *Listeners) Listen(network, address string) (net.Listener, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inherit()
+
+	key := network + ":" + address
+	if _, ok := l.listeners[key]; ok {
+		return nil, fmt.Errorf("already listening on %s", key)
+	}
+
+	var ln net.Listener
+	var err error
+	if f, ok := l.inherited[key]; ok {
+		delete(l.inherited, key)
+		ln, err = net.FileListener(f)
+		_ = f.Close()
+	} else {
+		ln, err = net.Listen(network, address)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	l.keys = append(l.keys, key)
+	l.listeners[key] = ln
+
+	return &listener{Listener: ln, registry: l, key: key}, nil
+}
+
+// unregister the listener ln registered under key, if it still is.
+func (l *Listeners) unregister(key string, ln net.Listener) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.listeners[key] != ln {
+		return
+	}
+	delete(l.listeners, key)
+	l.keys = slices.DeleteFunc(l.keys, func(k string) bool { return k == key })
+}
+
+// listener is a [net.Listener] registered with [Listeners] which is
+// unregistered once it is closed.
+type listener struct {
+	net.Listener
+	registry *Listeners
+	key      string
+}
+
+func (ln *listener) Close() error {
+	ln.registry.unregister(ln.key, ln.Listener)
+	return ln.Listener.Close()
+}
+
+// Ready tells the parent which started the process via [Listeners.Handoff]
+// that it is ready, such that the parent stops. Inherited listeners which have
+// not been adopted via [Listeners.Listen] are closed. It is a no-op if the
+// process was not started via Handoff or it was already called.
+func (l *Listeners) Ready() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inherit()
+
+	for key, f := range l.inherited {
+		_ = f.Close()
+		delete(l.inherited, key)
+	}
+	if l.readyFile == nil {
+		return nil
+	}
+
+	f := l.readyFile
+	l.readyFile = nil
+	_, err := f.Write([]byte{1})
+
+	return errors.Join(err, f.Close())
+}
+
+// Handoff re-executes the binary with the same arguments, passing it every
+// listener registered via [Listeners.Listen], then blocks until the child
+// calls [Listeners.Ready]. If the child exits before becoming ready or the
+// passed context is canceled first, the child is killed and a [HandoffError]
+// is returned, leaving the listeners of the parent unaffected.
+//
+// The child inherits the standard input, output, & error of the parent.
+func (l *Listeners) Handoff(ctx context.Context) error {
+	l.mu.Lock()
+	l.inherit()
+	files := make([]*os.File, 0, len(l.keys))
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+	for _, key := range l.keys {
+		ln := l.listeners[key]
+		if ul, ok := ln.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+		filer, ok := ln.(interface{ File() (*os.File, error) })
+		if !ok {
+			l.mu.Unlock()
+			return &HandoffError{Err: fmt.Errorf("listener on %s cannot be handed off", key)}
+		}
+		f, err := filer.File()
+		if err != nil {
+			l.mu.Unlock()
+			return &HandoffError{Err: err}
+		}
+		files = append(files, f)
+	}
+	keys := slices.Clone(l.keys)
+	command := l.command
+	l.mu.Unlock()
+
+	if command == nil {
+		command = reexec
+	}
+	cmd := command()
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return &HandoffError{Err: err}
+	}
+	defer readyR.Close()
+
+	cmd.Env = slices.DeleteFunc(slices.Clone(cmd.Environ()), func(kv string) bool {
+		return strings.HasPrefix(kv, listenersEnv+"=") || strings.HasPrefix(kv, readyFDEnv+"=")
+	})
+	cmd.Env = append(cmd.Env,
+		listenersEnv+"="+strings.Join(keys, ","),
+		readyFDEnv+"="+strconv.Itoa(3+len(files)),
+	)
+	cmd.ExtraFiles = append(slices.Clone(files), readyW)
+	err = cmd.Start()
+	_ = readyW.Close()
+	if err != nil {
+		return &HandoffError{Err: err}
+	}
+
+	readyCh := make(chan error, 1)
+	go func() {
+		n, err := readyR.Read(make([]byte, 1))
+		switch {
+		case n != 0:
+			readyCh <- nil
+		case errors.Is(err, io.EOF):
+			readyCh <- errors.New("child exited before becoming ready")
+		default:
+			readyCh <- err
+		}
+	}()
+
+	select {
+	case err = <-readyCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return &HandoffError{Err: err}
+	}
+
+	go func() { _ = cmd.Wait() }()
+
+	return nil
+}
+
+// reexec returns a command which runs the binary of the process with the same
+// arguments & standard streams.
+func reexec() *exec.Cmd {
+	path, err := os.Executable()
+	if err != nil {
+		path = os.Args[0]
+	}
+	cmd := exec.Command(path, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+	return cmd
+}
+
+// WithHandoff makes [Run] hand off the listeners registered with l to a
+// re-executed child process via [Listeners.Handoff] when any of signals are
+// received, such as [syscall.SIGUSR2]. Once the child is ready, stopping is
+// initiated as if a signal passed via [WithStopSignals] was received. If the
+// handoff fails, the parent keeps running and the [HandoffError] is passed to
+// the hook set via [WithHandoffErrorHook].
+//
+// It also makes [Run] call [Listeners.Ready] once the tree of [Runner] is ready
+// (see [Readier]), such that a parent which started the process via a handoff
+// stops.
+func WithHandoff(l *Listeners, signals ...os.Signal) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.listeners = l
+		cfg.handoffSignals = signals
+	}
+}
+
+// WithHandoffErrorHook sets the hook which is called with each error which
+// occurs handing off listeners via [WithHandoff].
+func WithHandoffErrorHook(hook func(err error)) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.handoffErrorHook = hook
+	}
+}
+
+// watchHandoffs hands off listeners each time a signal passed via [WithHandoff]
+// is received, returning a channel which receives the signal once a handoff
+// succeeds. It also marks the listeners as ready once readyCh receives nil.
+// Handoffs stop once the returned function is called, which cancels an
+// in-progress handoff then waits for it to finish.
+func watchHandoffs(ctx context.Context, cfg *RunConfig, readyCh <-chan error) (<-chan os.Signal, func()) {
+	handoffCh := make(chan os.Signal, 1)
+	if cfg.listeners == nil {
+		return handoffCh, func() {}
+	}
+
+	signalCh := make(chan os.Signal, 1)
+	if len(cfg.handoffSignals) != 0 {
+		signal.Notify(signalCh, cfg.handoffSignals...)
+	}
+	hook := func(err error) {
+		if err != nil && cfg.handoffErrorHook != nil {
+			cfg.handoffErrorHook(err)
+		}
+	}
+
+	handoffCtx, cancel := context.WithCancel(ctx)
+	doneCh := make(chan struct{})
+	go func() {
+		defer close(doneCh)
+		for {
+			select {
+			case err := <-readyCh:
+				if err == nil {
+					hook(cfg.listeners.Ready())
+				}
+				readyCh = nil
+			case sig := <-signalCh:
+				err := cfg.listeners.Handoff(handoffCtx)
+				if err == nil {
+					handoffCh <- sig
+					return
+				}
+				hook(err)
+			case <-handoffCtx.Done():
+				return
+			}
+		}
+	}()
+
+	return handoffCh, func() {
+		signal.Stop(signalCh)
+		cancel()
+		<-doneCh
+	}
+}
+
+// HandoffError occurs when listeners could not be handed off to a child
+// process via [Listeners.Handoff].
+type HandoffError struct {
+	Err error
+}
+
+func (e *HandoffError) Error() string {
+	return fmt.Sprintf("handoff failed: %v", e.Err)
+}
+
+func (e *HandoffError) Unwrap() error {
+	return e.Err
+}
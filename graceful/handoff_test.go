@@ -0,0 +1,224 @@
+//go:build unix
+
+package graceful_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wafer-bw/go-toolbox/graceful"
+)
+
+const handoffChildEnv = "GRACEFUL_TEST_HANDOFF_CHILD"
+
+// TestListeners_handoffChild is run as the child process by the handoff tests.
+// It adopts the listener handed off to it then serves a single connection,
+// unless it is told to exit before becoming ready.
+func TestListeners_handoffChild(t *testing.T) {
+	mode := os.Getenv(handoffChildEnv)
+	switch mode {
+	case "":
+		t.Skip("only run as a child process of handoff tests")
+	case "exit":
+		return
+	}
+
+	l := &graceful.Listeners{}
+	ln, err := l.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.Empty(t, os.Getenv("GRACEFUL_LISTENERS"), "not inherited by spawned processes")
+	require.Empty(t, os.Getenv("GRACEFUL_READY_FD"), "not inherited by spawned processes")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = graceful.Run(ctx, &graceful.Listener{
+		Listener: ln,
+		Handle: func(ctx context.Context, conn net.Conn) {
+			_, _ = conn.Write([]byte("child"))
+			cancel()
+		},
+	}, graceful.WithHandoff(l))
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+// handoffChild returns a command which runs [TestListeners_handoffChild] in
+// mode.
+func handoffChild(mode string) func() *exec.Cmd {
+	return func() *exec.Cmd {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestListeners_handoffChild$")
+		cmd.Env = append(os.Environ(), handoffChildEnv+"="+mode)
+		return cmd
+	}
+}
+
+// dial addr, returning everything read from the connection.
+func dial(t *testing.T, addr string) string {
+	t.Helper()
+
+	conn, err := net.DialTimeout("tcp", addr, time.Second)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+	b, err := io.ReadAll(conn)
+	require.NoError(t, err)
+
+	return string(b)
+}
+
+func TestListeners(t *testing.T) {
+	t.Run("hands off listeners to a child which adopts them", func(t *testing.T) {
+		l := &graceful.Listeners{}
+		graceful.SetHandoffCommand(l, handoffChild("serve"))
+		ln, err := l.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+		defer cancel()
+		require.NoError(t, l.Handoff(ctx))
+
+		addr := ln.Addr().String()
+		require.NoError(t, ln.Close())
+		require.Equal(t, "child", dial(t, addr))
+	})
+
+	t.Run("returns an error when the child exits before becoming ready", func(t *testing.T) {
+		l := &graceful.Listeners{}
+		graceful.SetHandoffCommand(l, handoffChild("exit"))
+		ln, err := l.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer ln.Close()
+
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+		defer cancel()
+		err = l.Handoff(ctx)
+		require.ErrorAs(t, err, new(*graceful.HandoffError))
+		require.ErrorContains(t, err, "child exited before becoming ready")
+	})
+
+	t.Run("returns an error when already listening on the address", func(t *testing.T) {
+		l := &graceful.Listeners{}
+		ln, err := l.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer ln.Close()
+
+		_, err = l.Listen("tcp", "127.0.0.1:0")
+		require.EqualError(t, err, "already listening on tcp:127.0.0.1:0")
+	})
+
+	t.Run("listens again once the listener is closed", func(t *testing.T) {
+		l := &graceful.Listeners{}
+		ln, err := l.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		require.NoError(t, ln.Close())
+
+		ln, err = l.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer ln.Close()
+	})
+
+	t.Run("hands off listeners which were not closed", func(t *testing.T) {
+		l := &graceful.Listeners{}
+		graceful.SetHandoffCommand(l, handoffChild("serve"))
+		closed, err := l.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		require.NoError(t, closed.Close())
+		ln, err := l.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+		defer cancel()
+		require.NoError(t, l.Handoff(ctx))
+
+		addr := ln.Addr().String()
+		require.NoError(t, ln.Close())
+		require.Equal(t, "child", dial(t, addr))
+	})
+
+	t.Run("is ready without a parent", func(t *testing.T) {
+		require.NoError(t, (&graceful.Listeners{}).Ready())
+	})
+}
+
+func TestWithHandoff(t *testing.T) {
+	t.Run("stops once listeners are handed off via signal", func(t *testing.T) {
+		l := &graceful.Listeners{}
+		graceful.SetHandoffCommand(l, handoffChild("serve"))
+		ln, err := l.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := ln.Addr().String()
+
+		stoppedCh := make(chan struct{})
+		r := graceful.RunnerType{
+			StartFunc: func(ctx context.Context) error {
+				<-stoppedCh
+				return nil
+			},
+			StopFunc: func(ctx context.Context) error {
+				close(stoppedCh)
+				return ln.Close()
+			},
+		}
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			p, err := os.FindProcess(syscall.Getpid())
+			require.NoError(t, err)
+			require.NoError(t, p.Signal(syscall.SIGUSR2))
+		}()
+
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+		defer cancel()
+		require.NoError(t, graceful.Run(ctx, r, graceful.WithHandoff(l, syscall.SIGUSR2)))
+		require.Equal(t, "child", dial(t, addr))
+	})
+
+	t.Run("keeps running when a handoff fails", func(t *testing.T) {
+		l := &graceful.Listeners{}
+		graceful.SetHandoffCommand(l, handoffChild("exit"))
+		ln, err := l.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer ln.Close()
+
+		errCh := make(chan error, 1)
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			p, err := os.FindProcess(syscall.Getpid())
+			require.NoError(t, err)
+			require.NoError(t, p.Signal(syscall.SIGUSR2))
+		}()
+
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		hookErrCh := make(chan error, 1)
+		go func() {
+			errCh <- graceful.Run(ctx, graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error {
+					<-ctx.Done()
+					return nil
+				},
+			}, graceful.WithHandoff(l, syscall.SIGUSR2), graceful.WithHandoffErrorHook(func(err error) {
+				hookErrCh <- err
+			}))
+		}()
+
+		require.ErrorAs(t, <-hookErrCh, new(*graceful.HandoffError))
+		require.Empty(t, errCh)
+		cancel()
+		require.ErrorIs(t, <-errCh, context.Canceled)
+	})
+}
+
+func TestHandoffError_Error(t *testing.T) {
+	t.Parallel()
+
+	err := &graceful.HandoffError{Err: errors.New("oh no")}
+	require.Equal(t, "handoff failed: oh no", err.Error())
+}
@@ -0,0 +1,148 @@
+package graceful
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WithSystemdNotify makes [Run] notify systemd of the lifecycle of the tree of
+// [Runner] via the sd_notify protocol when run as a systemd service with
+// Type=notify. It sends READY=1 once the tree is ready (see [Readier]) and
+// STOPPING=1 once stopping is initiated.
+//
+// If systemd's watchdog is enabled via WatchdogSec=, it also sends WATCHDOG=1
+// at half the watchdog interval for as long as no [Runner] in the tree has
+// failed (see [Monitor.Liveness]), so that systemd restarts the service
+// otherwise.
+//
+// Notifications are sent to the unix datagram socket named by the
+// NOTIFY_SOCKET environment variable and are best effort, nothing is sent when
+// it is unset and errors sending them are ignored.
+func WithSystemdNotify() RunOption {
+	return func(cfg *RunConfig) {
+		cfg.systemdNotify = true
+	}
+}
+
+// notifier sends notifications to systemd via the sd_notify protocol.
+type notifier struct {
+	conn       net.Conn
+	stoppingCh chan struct{}
+	stopCh     chan struct{}
+	doneCh     chan struct{}
+}
+
+// startNotifier returns a notifier which sends READY=1 once readyCh receives
+// nil and pings the watchdog (if enabled) while the tree is live until it is
+// closed. It returns nil if [WithSystemdNotify] was not used or NOTIFY_SOCKET
+// is unset.
+func startNotifier(ctx context.Context, cfg *RunConfig, readyCh <-chan error) *notifier {
+	if !cfg.systemdNotify {
+		return nil
+	}
+	addr := os.Getenv("NOTIFY_SOCKET")
+	if addr == "" {
+		return nil
+	}
+	if strings.HasPrefix(addr, "@") {
+		addr = "\x00" + addr[1:] // abstract namespace socket
+	}
+	conn, err := net.Dial("unixgram", addr)
+	if err != nil {
+		return nil
+	}
+
+	n := &notifier{
+		conn:       conn,
+		stoppingCh: make(chan struct{}),
+		stopCh:     make(chan struct{}),
+		doneCh:     make(chan struct{}),
+	}
+	interval := watchdogInterval()
+	go func() {
+		defer close(n.doneCh)
+
+		var tickCh <-chan time.Time
+		if interval > 0 {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			tickCh = ticker.C
+			n.ping(ctx, cfg.monitor)
+		}
+
+		stoppingCh := n.stoppingCh
+		for {
+			select {
+			case err := <-readyCh:
+				if err == nil {
+					n.notify("READY=1")
+				}
+				readyCh = nil
+			case <-stoppingCh:
+				n.notify("STOPPING=1")
+				readyCh, stoppingCh = nil, nil
+			case <-tickCh:
+				n.ping(ctx, cfg.monitor)
+			case <-n.stopCh:
+				select {
+				case <-stoppingCh:
+					n.notify("STOPPING=1")
+				default:
+				}
+				return
+			}
+		}
+	}()
+
+	return n
+}
+
+// notify systemd of state, such as READY=1.
+func (n *notifier) notify(state string) {
+	_, _ = n.conn.Write([]byte(state))
+}
+
+// stopping notifies systemd that stopping has been initiated. READY=1 is not
+// sent afterwards.
+func (n *notifier) stopping() {
+	if n == nil {
+		return
+	}
+	close(n.stoppingCh)
+}
+
+// ping the watchdog unless a runner in the tree has failed.
+func (n *notifier) ping(ctx context.Context, m *Monitor) {
+	if m.Liveness()(ctx) == nil {
+		n.notify("WATCHDOG=1")
+	}
+}
+
+// close stops notifying systemd.
+func (n *notifier) close() {
+	if n == nil {
+		return
+	}
+	close(n.stopCh)
+	<-n.doneCh
+	_ = n.conn.Close()
+}
+
+// watchdogInterval returns how often to ping systemd's watchdog, which is half
+// the interval it was configured with, or 0 if it is not enabled for this
+// process.
+func watchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+
+	return time.Duration(usec) * time.Microsecond / 2
+}
@@ -0,0 +1,133 @@
+package graceful_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wafer-bw/go-toolbox/graceful"
+)
+
+// notifySocket listens on a unix datagram socket standing in for systemd's,
+// pointing NOTIFY_SOCKET at it. It returns a channel which receives each
+// notification sent to it.
+func notifySocket(t *testing.T) <-chan string {
+	t.Helper()
+
+	dir, err := os.MkdirTemp("", "notify")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	addr := filepath.Join(dir, "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: addr, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", addr)
+
+	notifyCh := make(chan string, 64)
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				return
+			}
+			notifyCh <- string(buf[:n])
+		}
+	}()
+
+	return notifyCh
+}
+
+func TestWithSystemdNotify(t *testing.T) {
+	blocking := graceful.RunnerType{
+		StartFunc: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+	}
+
+	t.Run("notifies systemd once ready & once stopping", func(t *testing.T) {
+		notifyCh := notifySocket(t)
+		t.Setenv("WATCHDOG_USEC", "")
+
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		errCh := make(chan error, 1)
+		go func() { errCh <- graceful.Run(ctx, blocking, graceful.WithSystemdNotify()) }()
+
+		require.Equal(t, "READY=1", <-notifyCh)
+		cancel()
+		require.Equal(t, "STOPPING=1", <-notifyCh)
+		require.ErrorIs(t, <-errCh, context.Canceled)
+	})
+
+	t.Run("pings the watchdog until a runner fails", func(t *testing.T) {
+		notifyCh := notifySocket(t)
+		t.Setenv("WATCHDOG_USEC", "20000")
+		t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+
+		failCh, exitCh := make(chan struct{}), make(chan struct{})
+		s := &graceful.Supervisor{Children: []graceful.Child{
+			{Runner: graceful.RunnerType{StartFunc: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			}}},
+			{Runner: graceful.RunnerType{StartFunc: func(ctx context.Context) error {
+				defer close(exitCh)
+				<-failCh
+				return errors.New("start failed")
+			}}},
+		}}
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		errCh := make(chan error, 1)
+		go func() { errCh <- graceful.Run(ctx, s, graceful.WithSystemdNotify()) }()
+
+		pings := 0
+		for pings < 3 {
+			if <-notifyCh == "WATCHDOG=1" {
+				pings++
+			}
+		}
+
+		close(failCh)
+		<-exitCh
+		time.Sleep(20 * time.Millisecond)
+		for len(notifyCh) > 0 {
+			<-notifyCh
+		}
+		time.Sleep(50 * time.Millisecond)
+		require.Empty(t, notifyCh)
+
+		cancel()
+		<-errCh
+	})
+
+	t.Run("does not ping the watchdog of another process", func(t *testing.T) {
+		notifyCh := notifySocket(t)
+		t.Setenv("WATCHDOG_USEC", "20000")
+		t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()+1))
+
+		ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		defer cancel()
+		require.Error(t, graceful.Run(ctx, blocking, graceful.WithSystemdNotify()))
+		require.Equal(t, "READY=1", <-notifyCh)
+		require.Equal(t, "STOPPING=1", <-notifyCh)
+		require.Empty(t, notifyCh)
+	})
+
+	t.Run("does nothing when NOTIFY_SOCKET is unset", func(t *testing.T) {
+		t.Setenv("NOTIFY_SOCKET", "")
+
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, graceful.Run(ctx, blocking, graceful.WithSystemdNotify()), context.DeadlineExceeded)
+	})
+}
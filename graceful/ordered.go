@@ -0,0 +1,41 @@
+package graceful
+
+import "context"
+
+// StopOrder determines the order in which an [OrderedGroup] stops its
+// [Runner].
+type StopOrder int
+
+const (
+	// StopForward stops runners in series in the order they were declared.
+	StopForward StopOrder = iota
+	// StopReverse stops runners in series in the reverse of the order they
+	// were declared.
+	StopReverse
+	// StopParallel stops runners in parallel.
+	StopParallel
+)
+
+// OrderedGroup is a [Group] which stops its [Runner] in the provided
+// [StopOrder]. When Order is [StopParallel], at most Limit runners are stopped
+// at once unless Limit is less than or equal to 0.
+//
+// OrderedGroup satisfies [Runner] and thus it can be nested within a [Group] or
+// itself, allowing each level of a tree to use a different [StopOrder].
+type OrderedGroup struct {
+	Group Group
+	Order StopOrder
+	Limit int
+}
+
+// Start all [Runner] in parallel. See [Group.Start].
+func (g OrderedGroup) Start(ctx context.Context) error {
+	return g.Group.Start(ctx)
+}
+
+// Stop all [Runner] in the configured [StopOrder]. Blocks until all
+// [Runner.Stop] have returned normally, then returns the first non-nil error
+// (if any) from them.
+func (g OrderedGroup) Stop(ctx context.Context) error {
+	return stopAll(ctx, g.Group, g.Order, g.Limit)
+}
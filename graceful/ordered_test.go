@@ -0,0 +1,152 @@
+package graceful_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wafer-bw/go-toolbox/graceful"
+)
+
+// slowStopper returns a runner whose Stop takes a while, tracking the peak
+// number of concurrent stops.
+func slowStopper(stopping, peak *atomic.Int32) graceful.Runner {
+	return graceful.RunnerType{StopFunc: func(ctx context.Context) error {
+		n := stopping.Add(1)
+		defer stopping.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}}
+}
+
+func TestOrderedGroup_Start(t *testing.T) {
+	t.Parallel()
+
+	t.Run("starts all runners", func(t *testing.T) {
+		t.Parallel()
+
+		rec := &recorder{}
+		g := graceful.OrderedGroup{Group: graceful.Group{
+			graceful.RunnerType{StartFunc: func(ctx context.Context) error {
+				rec.record("a")
+				return nil
+			}},
+			graceful.RunnerType{StartFunc: func(ctx context.Context) error {
+				rec.record("b")
+				return nil
+			}},
+		}}
+
+		require.NoError(t, g.Start(t.Context()))
+		require.ElementsMatch(t, []string{"a", "b"}, rec.Events())
+	})
+}
+
+func TestOrderedGroup_Stop(t *testing.T) {
+	t.Parallel()
+
+	stopper := func(rec *recorder, name string) graceful.Runner {
+		return graceful.RunnerType{StopFunc: func(ctx context.Context) error {
+			rec.record(name)
+			return nil
+		}}
+	}
+
+	t.Run("stops in forward order", func(t *testing.T) {
+		t.Parallel()
+
+		rec := &recorder{}
+		g := graceful.OrderedGroup{
+			Order: graceful.StopForward,
+			Group: graceful.Group{stopper(rec, "a"), nil, stopper(rec, "b"), stopper(rec, "c")},
+		}
+
+		require.NoError(t, g.Stop(t.Context()))
+		require.Equal(t, []string{"a", "b", "c"}, rec.Events())
+	})
+
+	t.Run("stops in reverse order", func(t *testing.T) {
+		t.Parallel()
+
+		rec := &recorder{}
+		g := graceful.OrderedGroup{
+			Order: graceful.StopReverse,
+			Group: graceful.Group{stopper(rec, "a"), nil, stopper(rec, "b"), stopper(rec, "c")},
+		}
+
+		require.NoError(t, g.Stop(t.Context()))
+		require.Equal(t, []string{"c", "b", "a"}, rec.Events())
+	})
+
+	t.Run("stops in parallel", func(t *testing.T) {
+		t.Parallel()
+
+		peak := &atomic.Int32{}
+		slow := slowStopper(&atomic.Int32{}, peak)
+		g := graceful.OrderedGroup{
+			Order: graceful.StopParallel,
+			Group: graceful.Group{slow, slow, slow, nil},
+		}
+
+		require.NoError(t, g.Stop(t.Context()))
+		require.Equal(t, int32(3), peak.Load())
+	})
+
+	t.Run("stops in parallel with a limit", func(t *testing.T) {
+		t.Parallel()
+
+		peak := &atomic.Int32{}
+		slow := slowStopper(&atomic.Int32{}, peak)
+		g := graceful.OrderedGroup{
+			Order: graceful.StopParallel,
+			Limit: 2,
+			Group: graceful.Group{slow, slow, slow, slow},
+		}
+
+		require.NoError(t, g.Stop(t.Context()))
+		require.Equal(t, int32(2), peak.Load())
+	})
+
+	t.Run("returns first stop error encountered", func(t *testing.T) {
+		t.Parallel()
+
+		stopErr := errors.New("stop failed")
+		g := graceful.OrderedGroup{
+			Order: graceful.StopParallel,
+			Group: graceful.Group{
+				graceful.RunnerType{StopFunc: func(ctx context.Context) error { return stopErr }},
+				graceful.RunnerType{},
+			},
+		}
+
+		require.Equal(t, stopErr, g.Stop(t.Context()))
+	})
+
+	t.Run("allows nested groups to mix orders", func(t *testing.T) {
+		t.Parallel()
+
+		rec := &recorder{}
+		g := graceful.OrderedGroup{
+			Order: graceful.StopReverse,
+			Group: graceful.Group{
+				stopper(rec, "a"),
+				graceful.OrderedGroup{
+					Order: graceful.StopForward,
+					Group: graceful.Group{stopper(rec, "b"), stopper(rec, "c")},
+				},
+			},
+		}
+
+		require.NoError(t, g.Stop(t.Context()))
+		require.Equal(t, []string{"b", "c", "a"}, rec.Events())
+	})
+}
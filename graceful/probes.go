@@ -0,0 +1,93 @@
+package graceful
+
+import (
+	"context"
+	"fmt"
+)
+
+// ProbeFunc is an adapter type to allow the use of ordinary functions as
+// health checks. It satisfies the Prober interface of the
+// github.com/wafer-bw/go-toolbox/probe package so it can be used within a
+// probe.Group.
+type ProbeFunc func(ctx context.Context) error
+
+// Probe calls f(ctx).
+func (f ProbeFunc) Probe(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Readiness returns a probe which fails with an [UnhealthyError] until the tree
+// is running, whenever any [Runner] in it is not running, and once stopping
+// has been initiated. Members of a [DynamicGroup] which have exited are
+// excluded since the group contains their failures.
+func (m *Monitor) Readiness() ProbeFunc {
+	return func(ctx context.Context) error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		if m.stopping {
+			return &UnhealthyError{State: StateStopping}
+		}
+		root, ok := m.runners[""]
+		if !ok {
+			return &UnhealthyError{State: StateIdle}
+		}
+		if root.State != StateRunning {
+			return &UnhealthyError{State: root.State, Err: root.Err}
+		}
+		for _, rec := range m.snapshot() {
+			if m.runners[rec.Name].detached {
+				continue
+			}
+			switch rec.State {
+			case StateIdle, StateStarting, StateFailed:
+				return &UnhealthyError{Name: rec.Name, State: rec.State, Err: rec.Err}
+			}
+		}
+
+		return nil
+	}
+}
+
+// Liveness returns a probe which fails with an [UnhealthyError] whenever any
+// [Runner] in the tree has failed, such as a crashed [Child] of a [Supervisor]
+// which has not yet been restarted. Members of a [DynamicGroup] which have
+// exited are excluded since the group contains their failures.
+func (m *Monitor) Liveness() ProbeFunc {
+	return func(ctx context.Context) error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		for _, rec := range m.snapshot() {
+			if rec.State == StateFailed && !m.runners[rec.Name].detached {
+				return &UnhealthyError{Name: rec.Name, State: rec.State, Err: rec.Err}
+			}
+		}
+
+		return nil
+	}
+}
+
+// UnhealthyError occurs when a probe derived from a [Monitor] fails because the
+// [Runner] named Name is in State. A Name of "" refers to the tree itself.
+type UnhealthyError struct {
+	Name  string
+	State State
+	Err   error
+}
+
+func (e *UnhealthyError) Error() string {
+	msg := fmt.Sprintf("runner %s is %s", e.Name, e.State)
+	if e.Name == "" {
+		msg = fmt.Sprintf("tree is %s", e.State)
+	}
+	if e.Err != nil {
+		msg += fmt.Sprintf(": %v", e.Err)
+	}
+
+	return msg
+}
+
+func (e *UnhealthyError) Unwrap() error {
+	return e.Err
+}
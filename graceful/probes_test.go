@@ -0,0 +1,178 @@
+package graceful_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wafer-bw/go-toolbox/graceful"
+)
+
+func TestProbeFunc_Probe(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns the function error", func(t *testing.T) {
+		t.Parallel()
+
+		probeErr := errors.New("unhealthy")
+		f := graceful.ProbeFunc(func(ctx context.Context) error { return probeErr })
+		require.ErrorIs(t, f.Probe(t.Context()), probeErr)
+	})
+}
+
+func TestMonitor_Readiness(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fails until running then fails once stopping", func(t *testing.T) {
+		t.Parallel()
+
+		m := &graceful.Monitor{}
+		readiness := m.Readiness()
+		unhealthyErr := &graceful.UnhealthyError{}
+		require.ErrorAs(t, readiness.Probe(t.Context()), &unhealthyErr)
+		require.Equal(t, graceful.StateIdle, unhealthyErr.State)
+
+		readyCh := make(chan struct{})
+		stoppingCh := make(chan struct{})
+		probeErrCh := make(chan error, 1)
+		g := graceful.Group{
+			graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error {
+					<-ctx.Done()
+					return nil
+				},
+				ReadyFunc: func(ctx context.Context) error {
+					select {
+					case <-readyCh:
+						return nil
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				},
+				StopFunc: func(ctx context.Context) error {
+					probeErrCh <- readiness.Probe(ctx)
+					return nil
+				},
+			},
+		}
+
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- graceful.Run(ctx, g, graceful.WithMonitor(m), graceful.WithStoppingCh(stoppingCh))
+		}()
+
+		require.Eventually(t, func() bool {
+			s, ok := m.State("0")
+			return ok && s.State == graceful.StateStarting
+		}, time.Second, time.Millisecond)
+		require.Error(t, readiness.Probe(t.Context()))
+
+		close(readyCh)
+		require.Eventually(t, func() bool { return readiness.Probe(t.Context()) == nil }, time.Second, time.Millisecond)
+
+		cancel()
+		require.ErrorIs(t, <-errCh, context.Canceled)
+		require.ErrorAs(t, <-probeErrCh, &unhealthyErr)
+		require.Equal(t, graceful.StateStopping, unhealthyErr.State)
+	})
+
+	t.Run("succeeds once runners which do not opt into readiness are started", func(t *testing.T) {
+		t.Parallel()
+
+		m := &graceful.Monitor{}
+		readiness := m.Readiness()
+		r := graceful.RunnerType{
+			StartFunc: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			StopFunc: func(ctx context.Context) error { return nil },
+		}
+
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		errCh := make(chan error, 1)
+		go func() { errCh <- graceful.Run(ctx, graceful.Group{r}, graceful.WithMonitor(m)) }()
+
+		require.Eventually(t, func() bool { return readiness.Probe(t.Context()) == nil }, time.Second, time.Millisecond)
+		s, ok := m.State("0")
+		require.True(t, ok)
+		require.Equal(t, graceful.StateRunning, s.State)
+
+		cancel()
+		require.ErrorIs(t, <-errCh, context.Canceled)
+	})
+}
+
+func TestMonitor_Liveness(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fails while a runner has crashed", func(t *testing.T) {
+		t.Parallel()
+
+		crashErr := errors.New("crashed")
+		crashedCh := make(chan struct{})
+		m := &graceful.Monitor{}
+		liveness := m.Liveness()
+		require.NoError(t, liveness.Probe(t.Context()))
+
+		s := &graceful.Supervisor{
+			Children: []graceful.Child{
+				{
+					Runner: graceful.Named("a", graceful.RunnerType{
+						StartFunc: func(ctx context.Context) error {
+							select {
+							case <-crashedCh:
+								<-ctx.Done()
+								return nil
+							default:
+								close(crashedCh)
+								return crashErr
+							}
+						},
+					}),
+					Restart: graceful.RestartOnFailure,
+				},
+			},
+			MinBackoff: 50 * time.Millisecond,
+		}
+
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		errCh := make(chan error, 1)
+		go func() { errCh <- graceful.Run(ctx, s, graceful.WithMonitor(m)) }()
+
+		<-crashedCh
+		require.Eventually(t, func() bool {
+			unhealthyErr := &graceful.UnhealthyError{}
+			return errors.As(liveness.Probe(t.Context()), &unhealthyErr) &&
+				unhealthyErr.Name == "a" && errors.Is(unhealthyErr, crashErr)
+		}, time.Second, time.Millisecond)
+		require.Eventually(t, func() bool { return liveness.Probe(t.Context()) == nil }, time.Second, time.Millisecond)
+
+		cancel()
+		require.ErrorIs(t, <-errCh, context.Canceled)
+	})
+}
+
+func TestUnhealthyError_Error(t *testing.T) {
+	t.Parallel()
+
+	t.Run("includes the runner name, state, and error", func(t *testing.T) {
+		t.Parallel()
+
+		err := &graceful.UnhealthyError{Name: "a", State: graceful.StateFailed, Err: errors.New("boom")}
+		require.Equal(t, "runner a is failed: boom", err.Error())
+	})
+
+	t.Run("refers to the tree when the name is empty", func(t *testing.T) {
+		t.Parallel()
+
+		err := &graceful.UnhealthyError{State: graceful.StateStopping}
+		require.Equal(t, "tree is stopping", err.Error())
+	})
+}
@@ -0,0 +1,119 @@
+package graceful
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// Reloader is optionally implemented by a [Runner] which can reload itself,
+// such as by re-reading its configuration, without being stopped.
+type Reloader interface {
+	// Reload must terminate when the passed context is canceled or it
+	// completes (whichever happens first).
+	Reload(context.Context) error
+}
+
+// WithReloadSignals sets the signals which make [Run] reload the tree of
+// [Runner] rather than stop it, such as [syscall.SIGHUP].
+//
+// Reloading calls [Reloader.Reload] for every [Runner] in the tree which
+// implements [Reloader], in series in the order they were declared unless
+// [WithParallelReload] is used. Reload errors do not initiate stopping, they
+// are instead passed to the hook set via [WithReloadErrorHook]. Reloading stops
+// once stopping is initiated, canceling the context passed to an in-progress
+// [Reloader.Reload] then waiting for it to return.
+func WithReloadSignals(signals ...os.Signal) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.reloadSignals = signals
+	}
+}
+
+// WithParallelReload makes [Run] call [Reloader.Reload] for every [Runner] in
+// the tree in parallel when reloading. See [WithReloadSignals].
+func WithParallelReload() RunOption {
+	return func(cfg *RunConfig) {
+		cfg.parallelReload = true
+	}
+}
+
+// WithReloadErrorHook sets the hook which is called with each error returned
+// by a [Reloader.Reload] wrapped in a [RunnerError] identifying the runner.
+// When reloading in parallel, the hook may be called concurrently.
+func WithReloadErrorHook(hook func(err error)) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.reloadErrorHook = hook
+	}
+}
+
+// watchReloads reloads the tree rooted at r each time a signal passed via
+// [WithReloadSignals] is received until the returned function is called, which
+// cancels an in-progress reload then waits for it to finish.
+func watchReloads(ctx context.Context, r Runner, cfg *RunConfig) func() {
+	if len(cfg.reloadSignals) == 0 {
+		return func() {}
+	}
+
+	reloadCh := make(chan os.Signal, 1)
+	signal.Notify(reloadCh, cfg.reloadSignals...)
+	reloadCtx, cancel := context.WithCancel(ctx)
+	doneCh := make(chan struct{})
+	go func() {
+		defer close(doneCh)
+		for {
+			select {
+			case <-reloadCh:
+				reload(reloadCtx, r, cfg)
+			case <-reloadCtx.Done():
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(reloadCh)
+		cancel()
+		<-doneCh
+	}
+}
+
+// reload calls [Reloader.Reload] for every [Runner] in the tree rooted at r
+// which implements [Reloader].
+func reload(ctx context.Context, r Runner, cfg *RunConfig) {
+	type target struct {
+		ctx      context.Context
+		reloader Reloader
+	}
+
+	var targets []target
+	walk(ctx, r, func(ctx context.Context, r Runner) {
+		if reloader, ok := as[Reloader](r); ok {
+			targets = append(targets, target{ctx: ctx, reloader: reloader})
+		}
+	})
+
+	call := func(t target) {
+		err := protect(func() error { return t.reloader.Reload(t.ctx) })
+		if err != nil && t.ctx.Err() == nil && cfg.reloadErrorHook != nil {
+			cfg.reloadErrorHook(&RunnerError{Name: path(t.ctx), Op: "reload", Err: err})
+		}
+	}
+
+	if !cfg.parallelReload {
+		for _, t := range targets {
+			call(t)
+		}
+		return
+	}
+
+	wg := &sync.WaitGroup{}
+	for _, t := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			call(t)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,128 @@
+package graceful_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wafer-bw/go-toolbox/graceful"
+)
+
+func TestWithReloadSignals(t *testing.T) {
+	reloadTree := func(t *testing.T, opts ...graceful.RunOption) ([]string, []error) {
+		t.Helper()
+
+		mu := &sync.Mutex{}
+		var reloaded []string
+		var errs []error
+		reloadErr := errors.New("reload failed")
+		reloader := func(name string, err error) graceful.Runner {
+			return graceful.Named(name, graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error {
+					<-ctx.Done()
+					return nil
+				},
+				ReloadFunc: func(ctx context.Context) error {
+					mu.Lock()
+					defer mu.Unlock()
+					reloaded = append(reloaded, name)
+					return err
+				},
+			})
+		}
+		g := graceful.Group{
+			reloader("a", nil),
+			graceful.Stages{
+				reloader("b", reloadErr),
+				reloader("c", nil),
+			},
+		}
+
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			p, err := os.FindProcess(syscall.Getpid())
+			require.NoError(t, err)
+			require.NoError(t, p.Signal(syscall.SIGHUP))
+			require.Eventually(t, func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return len(reloaded) == 3
+			}, time.Second, time.Millisecond)
+			cancel()
+		}()
+
+		err := graceful.Run(ctx, g, append(opts,
+			graceful.WithReloadSignals(syscall.SIGHUP),
+			graceful.WithReloadErrorHook(func(err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				errs = append(errs, err)
+			}),
+		)...)
+		require.ErrorIs(t, err, context.Canceled)
+
+		mu.Lock()
+		defer mu.Unlock()
+		return reloaded, errs
+	}
+
+	t.Run("reloads every reloader in the tree in series without stopping", func(t *testing.T) {
+		reloaded, errs := reloadTree(t)
+		require.Equal(t, []string{"a", "b", "c"}, reloaded)
+		require.Len(t, errs, 1)
+
+		runnerErr := &graceful.RunnerError{}
+		require.ErrorAs(t, errs[0], &runnerErr)
+		require.Equal(t, "1/b", runnerErr.Name)
+		require.Equal(t, "reload", runnerErr.Op)
+	})
+
+	t.Run("reloads every reloader in the tree in parallel", func(t *testing.T) {
+		reloaded, errs := reloadTree(t, graceful.WithParallelReload())
+		require.ElementsMatch(t, []string{"a", "b", "c"}, reloaded)
+		require.Len(t, errs, 1)
+	})
+
+	t.Run("cancels an in-progress reload once stopping is initiated", func(t *testing.T) {
+		reloadingCh := make(chan struct{})
+		reloadErrCh := make(chan error, 1)
+		r := graceful.RunnerType{
+			StartFunc: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			ReloadFunc: func(ctx context.Context) error {
+				close(reloadingCh)
+				<-ctx.Done()
+				reloadErrCh <- ctx.Err()
+				return ctx.Err()
+			},
+		}
+
+		ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+		defer cancel()
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			p, err := os.FindProcess(syscall.Getpid())
+			require.NoError(t, err)
+			require.NoError(t, p.Signal(syscall.SIGHUP))
+			<-reloadingCh
+			require.NoError(t, p.Signal(os.Interrupt))
+		}()
+
+		err := graceful.Run(ctx, r,
+			graceful.WithStopSignals(os.Interrupt),
+			graceful.WithReloadSignals(syscall.SIGHUP),
+		)
+		require.NoError(t, err)
+		require.NoError(t, ctx.Err())
+		require.ErrorIs(t, <-reloadErrCh, context.Canceled)
+	})
+}
@@ -0,0 +1,47 @@
+package graceful
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Stages of [Runner] which are started in order, each only once the previous
+// stage is ready (see [Readier]), & stopped in reverse order.
+//
+// A stage is typically a [Group] so that the runners within it are started
+// in parallel and the stage is ready once all of them are.
+//
+// Stages satisfies [Runner] and thus it can be nested within a [Group] or
+// itself to create a tree. Stages is ready once its last stage is ready.
+type Stages []Runner
+
+// Start each stage in order, waiting for each to be ready before starting the
+// next. Blocks until all started [Runner.Start] have returned normally, then
+// returns the first non-nil error (if any) from them.
+//
+// If a stage fails to become ready, no further stages are started and its
+// error is returned immediately. The stages which were already started keep
+// running until they are stopped.
+func (s Stages) Start(ctx context.Context) error {
+	eg := new(errgroup.Group)
+	for i, r := range s {
+		if r == nil {
+			continue
+		}
+		start, ready := withReady(ctx, nameOf(r, i), r)
+		eg.Go(start)
+		if err := <-ready; err != nil {
+			return err
+		}
+	}
+	MarkReady(ctx)
+
+	return eg.Wait()
+}
+
+// Stop each stage in reverse order. Blocks until all [Runner.Stop] have
+// returned normally, then returns the first non-nil error (if any) from them.
+func (s Stages) Stop(ctx context.Context) error {
+	return stopAll(ctx, s, StopReverse, 0)
+}
@@ -0,0 +1,279 @@
+package graceful_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wafer-bw/go-toolbox/graceful"
+)
+
+// recorder records the order of events across runners.
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) Events() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+func TestStages_Start(t *testing.T) {
+	t.Parallel()
+
+	t.Run("starts each stage once the previous stage is ready", func(t *testing.T) {
+		t.Parallel()
+
+		rec := &recorder{}
+		dbReadyCh := make(chan struct{})
+		s := graceful.Stages{
+			graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error {
+					rec.record("db start")
+					go func() {
+						time.Sleep(25 * time.Millisecond)
+						rec.record("db ready")
+						close(dbReadyCh)
+					}()
+					<-ctx.Done()
+					return nil
+				},
+				ReadyFunc: func(ctx context.Context) error {
+					<-dbReadyCh
+					return nil
+				},
+			},
+			graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error {
+					rec.record("http start")
+					<-ctx.Done()
+					return nil
+				},
+			},
+		}
+
+		ctx, cancel := context.WithCancel(t.Context())
+		errCh := make(chan error)
+		go func() { errCh <- s.Start(ctx) }()
+
+		require.Eventually(t, func() bool { return len(rec.Events()) == 3 }, time.Second, time.Millisecond)
+		cancel()
+		require.NoError(t, <-errCh)
+		require.Equal(t, []string{"db start", "db ready", "http start"}, rec.Events())
+	})
+
+	t.Run("waits for awaited runners to call MarkReady", func(t *testing.T) {
+		t.Parallel()
+
+		rec := &recorder{}
+		s := graceful.Stages{
+			graceful.AwaitReady(graceful.RunnerType{StartFunc: func(ctx context.Context) error {
+				time.Sleep(25 * time.Millisecond)
+				rec.record("cache warm")
+				graceful.MarkReady(ctx)
+				<-ctx.Done()
+				return nil
+			}}),
+			graceful.RunnerType{StartFunc: func(ctx context.Context) error {
+				rec.record("http start")
+				return nil
+			}},
+		}
+
+		ctx, cancel := context.WithCancel(t.Context())
+		errCh := make(chan error)
+		go func() { errCh <- s.Start(ctx) }()
+
+		require.Eventually(t, func() bool { return len(rec.Events()) == 2 }, time.Second, time.Millisecond)
+		cancel()
+		require.NoError(t, <-errCh)
+		require.Equal(t, []string{"cache warm", "http start"}, rec.Events())
+	})
+
+	t.Run("does not wait for runners which do not opt into readiness", func(t *testing.T) {
+		t.Parallel()
+
+		startedCh := make(chan struct{})
+		s := graceful.Stages{
+			graceful.RunnerType{StartFunc: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			}},
+			graceful.RunnerType{StartFunc: func(ctx context.Context) error {
+				close(startedCh)
+				<-ctx.Done()
+				return nil
+			}},
+		}
+
+		ctx, cancel := context.WithCancel(t.Context())
+		errCh := make(chan error)
+		go func() { errCh <- s.Start(ctx) }()
+
+		<-startedCh
+		cancel()
+		require.NoError(t, <-errCh)
+	})
+
+	t.Run("waits for all runners of a group stage to be ready", func(t *testing.T) {
+		t.Parallel()
+
+		rec := &recorder{}
+		s := graceful.Stages{
+			graceful.Group{
+				graceful.AwaitReady(graceful.RunnerType{StartFunc: func(ctx context.Context) error {
+					time.Sleep(25 * time.Millisecond)
+					rec.record("a ready")
+					graceful.MarkReady(ctx)
+					<-ctx.Done()
+					return nil
+				}}),
+				graceful.AwaitReady(graceful.RunnerType{StartFunc: func(ctx context.Context) error {
+					time.Sleep(50 * time.Millisecond)
+					rec.record("b done")
+					return nil
+				}}),
+			},
+			graceful.RunnerType{StartFunc: func(ctx context.Context) error {
+				rec.record("c start")
+				return nil
+			}},
+		}
+
+		ctx, cancel := context.WithCancel(t.Context())
+		errCh := make(chan error)
+		go func() { errCh <- s.Start(ctx) }()
+
+		require.Eventually(t, func() bool { return len(rec.Events()) == 3 }, time.Second, time.Millisecond)
+		cancel()
+		require.NoError(t, <-errCh)
+		require.Equal(t, []string{"a ready", "b done", "c start"}, rec.Events())
+	})
+
+	t.Run("does not start later stages when a stage fails", func(t *testing.T) {
+		t.Parallel()
+
+		startErr := errors.New("start failed")
+		started := false
+		s := graceful.Stages{
+			graceful.AwaitReady(graceful.RunnerType{StartFunc: func(ctx context.Context) error { return startErr }}),
+			graceful.RunnerType{StartFunc: func(ctx context.Context) error {
+				started = true
+				return nil
+			}},
+		}
+
+		err := s.Start(t.Context())
+		require.Equal(t, startErr, err)
+		require.False(t, started)
+	})
+
+	t.Run("returns ready error without waiting for started stages", func(t *testing.T) {
+		t.Parallel()
+
+		readyErr := errors.New("not ready")
+		started := false
+		s := graceful.Stages{
+			graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error {
+					<-ctx.Done()
+					return nil
+				},
+				ReadyFunc: func(ctx context.Context) error { return readyErr },
+			},
+			graceful.RunnerType{StartFunc: func(ctx context.Context) error {
+				started = true
+				return nil
+			}},
+		}
+
+		err := s.Start(t.Context())
+		require.Equal(t, readyErr, err)
+		require.False(t, started)
+	})
+
+	t.Run("stops the tree when run and a stage fails to become ready", func(t *testing.T) {
+		t.Parallel()
+
+		readyErr := errors.New("not ready")
+		s := graceful.Stages{
+			graceful.Named("db", graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error {
+					<-ctx.Done()
+					return nil
+				},
+				ReadyFunc: func(ctx context.Context) error { return readyErr },
+			}),
+			graceful.RunnerType{},
+		}
+
+		ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+		defer cancel()
+
+		err := graceful.Run(ctx, s)
+		reason := &graceful.ShutdownReason{}
+		require.ErrorAs(t, err, &reason)
+		require.Equal(t, "db", reason.Runner)
+		require.ErrorIs(t, err, readyErr)
+		require.NoError(t, ctx.Err())
+	})
+
+	t.Run("does not panic when runners are nil", func(t *testing.T) {
+		t.Parallel()
+
+		s := graceful.Stages{nil, nil}
+		require.NotPanics(t, func() {
+			err := s.Start(t.Context())
+			require.NoError(t, err)
+		})
+	})
+}
+
+func TestStages_Stop(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stops stages in reverse order", func(t *testing.T) {
+		t.Parallel()
+
+		rec := &recorder{}
+		s := graceful.Stages{
+			graceful.RunnerType{StopFunc: func(ctx context.Context) error {
+				rec.record("a")
+				return nil
+			}},
+			nil,
+			graceful.RunnerType{StopFunc: func(ctx context.Context) error {
+				rec.record("b")
+				return nil
+			}},
+		}
+
+		err := s.Stop(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, []string{"b", "a"}, rec.Events())
+	})
+
+	t.Run("returns first stop error encountered", func(t *testing.T) {
+		t.Parallel()
+
+		aErr, bErr := errors.New("a failed"), errors.New("b failed")
+		s := graceful.Stages{
+			graceful.RunnerType{StopFunc: func(ctx context.Context) error { return aErr }},
+			graceful.RunnerType{StopFunc: func(ctx context.Context) error { return bErr }},
+		}
+
+		err := s.Stop(t.Context())
+		require.Equal(t, bErr, err)
+	})
+}
@@ -0,0 +1,360 @@
+package graceful
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// State of a [Runner] within a tree run via [Run].
+type State int
+
+const (
+	// StateIdle means [Runner.Start] has not been called.
+	StateIdle State = iota
+	// StateStarting means [Runner.Start] has been called but the runner is
+	// not yet ready (see [Readier]).
+	StateStarting
+	// StateRunning means the runner is ready.
+	StateRunning
+	// StateStopping means [Runner.Stop] has been called but has not yet
+	// returned.
+	StateStopping
+	// StateStopped means [Runner.Stop] returned nil, [Runner.Start] returned
+	// nil on its own, or the runner was stopped before it was started.
+	StateStopped
+	// StateFailed means [Runner.Start] or [Runner.Stop] returned an error.
+	StateFailed
+)
+
+func (s State) String() string {
+	switch s {
+	case StateIdle:
+		return "idle"
+	case StateStarting:
+		return "starting"
+	case StateRunning:
+		return "running"
+	case StateStopping:
+		return "stopping"
+	case StateStopped:
+		return "stopped"
+	case StateFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// RunnerState is a snapshot of the [State] of a [Runner] within a tree.
+type RunnerState struct {
+	// Name of the runner (see [RunnerError]).
+	Name string
+	// State of the runner.
+	State State
+	// Since is when the runner entered its current state.
+	Since time.Time
+	// Err which caused the runner to enter [StateFailed], if any.
+	Err error
+}
+
+// WithMonitor sets the [Monitor] which [Run] will track the [State] of every
+// [Runner] in the tree with.
+func WithMonitor(m *Monitor) RunOption {
+	return func(cfg *RunConfig) {
+		cfg.monitor = m
+	}
+}
+
+// Monitor tracks the [State] of every [Runner] within a tree run via [Run] and
+// enforces valid transitions between them:
+//   - Calling [Runner.Start] while a previous call to it has not returned
+//     returns a [TransitionError] without calling it again.
+//   - Calling [Runner.Start] after [Runner.Stop] was called on a runner which
+//     had not yet been started calls it with an already canceled
+//     [context.Context] so that it returns promptly. Its cause is the
+//     [ShutdownReason] if stopping of the tree has been initiated, otherwise a
+//     [TransitionError]. The runner remains in [StateStopped].
+//   - Calling [Runner.Stop] again before [Runner.Start] is called again
+//     returns nil without calling it again.
+//
+// Runners are identified by their name (see [RunnerError]) so the runners
+// within each [Group], [Stages], [Graph], & [Supervisor] must have unique
+// names. The zero value is ready to use and a Monitor must not be copied after
+// first use or shared between calls to [Run].
+type Monitor struct {
+	mu       sync.Mutex
+	runners  map[string]*record
+	stopping bool
+}
+
+// record of the lifecycle of a single runner.
+type record struct {
+	RunnerState
+
+	started  bool // whether Start has ever been called
+	starting bool // whether a call to Start has yet to return
+	stopped  bool // whether Stop has been called since Start was last called
+	detached bool // whether it is excluded from probes
+}
+
+// Snapshot returns the state of every [Runner] which the monitor has seen,
+// ordered by name. The tree itself is named "".
+func (m *Monitor) Snapshot() []RunnerState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.snapshot()
+}
+
+// snapshot must be called with mu held.
+func (m *Monitor) snapshot() []RunnerState {
+	states := make([]RunnerState, 0, len(m.runners))
+	for _, rec := range m.runners {
+		states = append(states, rec.RunnerState)
+	}
+	slices.SortFunc(states, func(a, b RunnerState) int { return strings.Compare(a.Name, b.Name) })
+
+	return states
+}
+
+// State returns the state of the [Runner] named name, or false if the monitor
+// has not seen it.
+func (m *Monitor) State(name string) (RunnerState, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rec, ok := m.runners[name]
+	if !ok {
+		return RunnerState{}, false
+	}
+
+	return rec.RunnerState, true
+}
+
+// monitor returns the [Monitor] of the [Run] which ctx descends from, if any.
+func monitor(ctx context.Context) *Monitor {
+	state, ok := getRunState(ctx)
+	if !ok {
+		return nil
+	}
+
+	return state.cfg.monitor
+}
+
+// pending returns the names of the runners, other than the tree itself, which
+// are idle or starting.
+func (m *Monitor) pending() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var names []string
+	for _, rs := range m.snapshot() {
+		if rs.Name != "" && (rs.State == StateIdle || rs.State == StateStarting) {
+			names = append(names, rs.Name)
+		}
+	}
+
+	return names
+}
+
+// markStopping records that stopping has been initiated for the tree.
+func (m *Monitor) markStopping() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.stopping = true
+}
+
+// record returns the record of the runner named name. It must be called with
+// mu held.
+func (m *Monitor) record(name string) *record {
+	if m.runners == nil {
+		m.runners = map[string]*record{}
+	}
+	rec, ok := m.runners[name]
+	if !ok {
+		rec = &record{RunnerState: RunnerState{Name: name, Since: time.Now()}}
+		m.runners[name] = rec
+	}
+
+	return rec
+}
+
+func (r *record) set(state State, err error) {
+	r.State, r.Since, r.Err = state, time.Now(), err
+}
+
+// beginStart records that [Runner.Start] of the runner named name is about to
+// be called. If the runner was stopped before being started, a
+// [TransitionError] is returned for use as the cause of the canceled context
+// it must be started with. If it is already being started, a
+// [TransitionError] is returned as an error instead.
+func (m *Monitor) beginStart(name string) (*TransitionError, error) {
+	if m == nil {
+		return nil, nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rec := m.record(name)
+	switch {
+	case rec.starting:
+		return nil, &TransitionError{Name: name, From: rec.State, To: StateStarting}
+	case rec.stopped && !rec.started:
+		rec.started, rec.starting = true, true
+		return &TransitionError{Name: name, From: rec.State, To: StateStarting}, nil
+	}
+	rec.started, rec.starting, rec.stopped = true, true, false
+	rec.set(StateStarting, nil)
+
+	return nil, nil
+}
+
+// preempted returns a [TransitionError] for use as the cause of the canceled
+// context the runner named name must be started with if it was stopped after
+// [Monitor.beginStart] recorded its start but before [Runner.Start] is called.
+func (m *Monitor) preempted(name string) *TransitionError {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rec := m.record(name)
+	if !rec.starting || !rec.stopped {
+		return nil
+	}
+
+	return &TransitionError{Name: name, From: rec.State, To: StateStarting}
+}
+
+// ready marks the runner named name as running if it is still starting.
+func (m *Monitor) ready(name string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if rec, ok := m.runners[name]; ok && rec.State == StateStarting {
+		rec.set(StateRunning, nil)
+	}
+}
+
+// endStart records that [Runner.Start] of the runner named name returned err.
+func (m *Monitor) endStart(name string, err error) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rec, ok := m.runners[name]
+	if !ok {
+		return
+	}
+	rec.starting = false
+	if rec.stopped {
+		return
+	}
+	if err != nil {
+		rec.set(StateFailed, err)
+	} else {
+		rec.set(StateStopped, nil)
+	}
+}
+
+// beginStop reports whether [Runner.Stop] of the runner named name should be
+// skipped because it has already been stopped.
+func (m *Monitor) beginStop(name string) bool {
+	if m == nil {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rec := m.record(name)
+	if rec.stopped {
+		return true
+	}
+	rec.stopped = true
+	rec.set(StateStopping, nil)
+
+	return false
+}
+
+// endStop records that [Runner.Stop] of the runner named name returned err.
+func (m *Monitor) endStop(name string, err error) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rec, ok := m.runners[name]
+	switch {
+	case !ok:
+	case err != nil:
+		rec.set(StateFailed, err)
+	default:
+		rec.set(StateStopped, nil)
+	}
+}
+
+// detach excludes the runner named name and those nested within it from
+// [Monitor.Readiness] & [Monitor.Liveness].
+func (m *Monitor) detach(name string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for n, rec := range m.runners {
+		if within(n, name) {
+			rec.detached = true
+		}
+	}
+}
+
+// forget the runner named name and those nested within it.
+func (m *Monitor) forget(name string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for n := range m.runners {
+		if within(n, name) {
+			delete(m.runners, n)
+		}
+	}
+}
+
+// within reports whether the runner named name is the runner named parent or
+// is nested within it.
+func within(name, parent string) bool {
+	return name == parent || strings.HasPrefix(name, parent+"/")
+}
+
+// TransitionError occurs when a [Runner] is asked to make an invalid
+// transition between [State], such as being started while it is already
+// starting or running.
+type TransitionError struct {
+	Name string
+	From State
+	To   State
+}
+
+func (e *TransitionError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("cannot transition from %s to %s", e.From, e.To)
+	}
+
+	return fmt.Sprintf("runner %s cannot transition from %s to %s", e.Name, e.From, e.To)
+}
@@ -0,0 +1,169 @@
+package graceful_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wafer-bw/go-toolbox/graceful"
+)
+
+func TestState_String(t *testing.T) {
+	t.Parallel()
+
+	for state, want := range map[graceful.State]string{
+		graceful.StateIdle:     "idle",
+		graceful.StateStarting: "starting",
+		graceful.StateRunning:  "running",
+		graceful.StateStopping: "stopping",
+		graceful.StateStopped:  "stopped",
+		graceful.StateFailed:   "failed",
+		graceful.State(-1):     "unknown",
+	} {
+		require.Equal(t, want, state.String())
+	}
+}
+
+func TestMonitor(t *testing.T) {
+	t.Parallel()
+
+	states := func(m *graceful.Monitor) map[string]graceful.State {
+		s := map[string]graceful.State{}
+		for _, rs := range m.Snapshot() {
+			require.False(t, rs.Since.IsZero())
+			s[rs.Name] = rs.State
+		}
+		return s
+	}
+
+	t.Run("tracks the state of every runner in the tree", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		m := &graceful.Monitor{}
+		stopErr := errors.New("stop failed")
+		blocking := graceful.RunnerType{
+			StartFunc: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+		}
+		g := graceful.Group{
+			graceful.Named("a", blocking),
+			graceful.Named("b", graceful.RunnerType{
+				StartFunc: blocking.StartFunc,
+				StopFunc:  func(ctx context.Context) error { return stopErr },
+			}),
+		}
+
+		errCh := make(chan error, 1)
+		go func() { errCh <- graceful.Run(ctx, g, graceful.WithMonitor(m)) }()
+
+		require.Eventually(t, func() bool {
+			s, ok := m.State("")
+			return ok && s.State == graceful.StateRunning
+		}, time.Second, time.Millisecond)
+		require.Equal(t, map[string]graceful.State{
+			"":  graceful.StateRunning,
+			"a": graceful.StateRunning,
+			"b": graceful.StateRunning,
+		}, states(m))
+
+		cancel()
+		require.Error(t, <-errCh)
+		require.Equal(t, map[string]graceful.State{
+			"":  graceful.StateFailed,
+			"a": graceful.StateStopped,
+			"b": graceful.StateFailed,
+		}, states(m))
+
+		b, ok := m.State("b")
+		require.True(t, ok)
+		require.ErrorIs(t, b.Err, stopErr)
+	})
+
+	t.Run("returns false for unknown runners", func(t *testing.T) {
+		t.Parallel()
+
+		_, ok := (&graceful.Monitor{}).State("a")
+		require.False(t, ok)
+	})
+
+	t.Run("returns a transition error when starting a runner which is already starting", func(t *testing.T) {
+		t.Parallel()
+
+		starts := &atomic.Int32{}
+		r := graceful.RunnerType{
+			StartFunc: func(ctx context.Context) error {
+				starts.Add(1)
+				<-ctx.Done()
+				return nil
+			},
+		}
+
+		ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		defer cancel()
+		err := graceful.Run(ctx, graceful.Group{graceful.Named("a", r), graceful.Named("a", r)},
+			graceful.WithJoinedErrors(),
+		)
+		transitionErr := &graceful.TransitionError{}
+		require.ErrorAs(t, err, &transitionErr)
+		require.Equal(t, "a", transitionErr.Name)
+		require.Equal(t, graceful.StateStarting, transitionErr.To)
+		require.Equal(t, int32(1), starts.Load())
+	})
+
+	t.Run("starts runners stopped before being started with a canceled context", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		causeCh := make(chan error, 1)
+		stoppedCh := make(chan struct{})
+		r := graceful.RunnerType{
+			StartFunc: func(ctx context.Context) error {
+				<-stoppedCh
+				<-ctx.Done()
+				causeCh <- context.Cause(ctx)
+				return nil
+			},
+			StopFunc: func(ctx context.Context) error {
+				close(stoppedCh)
+				return nil
+			},
+		}
+
+		m := &graceful.Monitor{}
+		err := graceful.Run(ctx, graceful.Group{graceful.Named("a", r)}, graceful.WithMonitor(m))
+		require.ErrorIs(t, err, context.Canceled)
+		reason := &graceful.ShutdownReason{}
+		require.ErrorAs(t, <-causeCh, &reason)
+		require.True(t, reason.Canceled)
+
+		a, ok := m.State("a")
+		require.True(t, ok)
+		require.Equal(t, graceful.StateStopped, a.State)
+	})
+}
+
+func TestTransitionError_Error(t *testing.T) {
+	t.Parallel()
+
+	t.Run("includes the runner name", func(t *testing.T) {
+		t.Parallel()
+
+		err := &graceful.TransitionError{Name: "a", From: graceful.StateRunning, To: graceful.StateStarting}
+		require.Equal(t, "runner a cannot transition from running to starting", err.Error())
+	})
+
+	t.Run("omits the runner name when empty", func(t *testing.T) {
+		t.Parallel()
+
+		err := &graceful.TransitionError{From: graceful.StateRunning, To: graceful.StateStarting}
+		require.Equal(t, "cannot transition from running to starting", err.Error())
+	})
+}
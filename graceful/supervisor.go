@@ -0,0 +1,354 @@
+package graceful
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// RestartPolicy determines whether a [Supervisor] restarts a [Child] once its
+// [Runner.Start] returns.
+type RestartPolicy int
+
+const (
+	// RestartNever never restarts the child.
+	RestartNever RestartPolicy = iota
+	// RestartOnFailure restarts the child only when [Runner.Start] returns an
+	// error.
+	RestartOnFailure
+	// RestartAlways restarts the child whenever [Runner.Start] returns.
+	RestartAlways
+)
+
+// Strategy determines which children a [Supervisor] restarts when one of its
+// children needs to be restarted.
+type Strategy int
+
+const (
+	// OneForOne restarts only the child which returned.
+	OneForOne Strategy = iota
+	// OneForAll stops all other children then restarts all children.
+	// Children with [RestartNever] are stopped but not restarted.
+	OneForAll
+	// RestForOne stops all children after the one which returned then
+	// restarts it and all children after it. Children with [RestartNever] are
+	// stopped but not restarted.
+	RestForOne
+)
+
+// Child is a [Runner] supervised by a [Supervisor] along with its
+// [RestartPolicy].
+//
+// The runner must be capable of being started again after its [Runner.Start]
+// has returned or its [Runner.Stop] has been called.
+type Child struct {
+	Runner  Runner
+	Restart RestartPolicy
+}
+
+// defaultMinBackoff & defaultMaxBackoff are used by a [Supervisor] whose
+// MinBackoff or MaxBackoff are zero.
+const (
+	defaultMinBackoff = 100 * time.Millisecond
+	defaultMaxBackoff = 10 * time.Second
+)
+
+// Supervisor of [Child] runners which restarts them according to their
+// [RestartPolicy] and its [Strategy], in the style of Erlang/OTP supervisors.
+//
+// If more than MaxRestarts restarts occur within Window, the supervisor gives
+// up and [Supervisor.Start] returns a [RestartIntensityError]. A MaxRestarts of
+// zero means there is no limit and a Window of zero means all restarts are
+// counted. Before each restart the supervisor waits for an exponential backoff
+// which starts at MinBackoff & doubles with each restart of the child within
+// Window up to MaxBackoff. A MinBackoff of zero defaults to 100ms and a
+// MaxBackoff of zero defaults to 10s so that a child which keeps exiting is not
+// restarted in a tight loop.
+//
+// Supervisor satisfies [Runner] and thus it can be nested within a [Group] or
+// itself to create a tree. Supervisor is ready once all of its children have
+// initially become ready. A Supervisor must not be copied after first use.
+type Supervisor struct {
+	Children    []Child
+	Strategy    Strategy
+	MaxRestarts int
+	Window      time.Duration
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+
+	mu       sync.Mutex
+	stopping bool
+	stopCh   chan struct{}
+
+	beforeRestart func() // for testing.
+}
+
+func (s *Supervisor) children() []child {
+	runners := make([]Runner, len(s.Children))
+	for i, c := range s.Children {
+		runners[i] = c.Runner
+	}
+
+	return childrenOf(runners)
+}
+
+type exit struct {
+	i   int
+	err error
+}
+
+// Start all children in parallel, restarting them as they return according to
+// their [RestartPolicy] & the [Strategy] of the supervisor.
+//
+// Blocks until all children have returned without being restarted, then
+// returns the first non-nil error (if any) from them. Children are not
+// restarted once [Supervisor.Stop] has been called or the passed context is
+// canceled. If the restart intensity is exceeded, a [RestartIntensityError] is
+// returned immediately and any children still running are left for
+// [Supervisor.Stop] to stop.
+func (s *Supervisor) Start(ctx context.Context) error {
+	stopCh, stopping := s.init()
+	if stopping {
+		return nil
+	}
+
+	exits := make(chan exit, len(s.Children))
+	running := make([]bool, len(s.Children))
+	start := func(i int) <-chan error {
+		r := s.Children[i].Runner
+		if r == nil {
+			return nil
+		}
+		running[i] = true
+		start, ready := withReady(contain(ctx), nameOf(r, i), r)
+		go func() { exits <- exit{i: i, err: start()} }()
+		return ready
+	}
+
+	readies := make([]<-chan error, 0, len(s.Children))
+	for i := range s.Children {
+		if ready := start(i); ready != nil {
+			readies = append(readies, ready)
+		}
+	}
+	go func() {
+		for _, ready := range readies {
+			if err := <-ready; err != nil {
+				return
+			}
+		}
+		MarkReady(ctx)
+	}()
+
+	var pending []exit
+	next := func() exit {
+		if len(pending) > 0 {
+			e := pending[0]
+			pending = pending[1:]
+			return e
+		}
+		return <-exits
+	}
+
+	var firstErr error
+	var restarts []time.Time
+	childRestarts := make([][]time.Time, len(s.Children))
+	for {
+		if !slices.Contains(running, true) {
+			return firstErr
+		}
+
+		e := next()
+		running[e.i] = false
+		if !s.shouldRestart(ctx, stopCh, e) {
+			if e.err != nil && firstErr == nil {
+				firstErr = e.err
+			}
+			continue
+		}
+
+		now := time.Now()
+		restarts = append(withinWindow(restarts, now, s.Window), now)
+		if s.MaxRestarts > 0 && len(restarts) > s.MaxRestarts {
+			err := &RestartIntensityError{MaxRestarts: s.MaxRestarts, Window: s.Window, Err: e.err}
+			report(ctx, "start", err)
+			return err
+		}
+		childRestarts[e.i] = append(withinWindow(childRestarts[e.i], now, s.Window), now)
+
+		restartSet := s.restartSet(e.i)
+		for j := len(restartSet) - 1; j >= 0; j-- {
+			if i := restartSet[j]; running[i] {
+				_ = stopRunner(ctx, nameOf(s.Children[i].Runner, i), s.Children[i].Runner, 0)
+			}
+		}
+		for _, i := range restartSet {
+			for running[i] {
+				sibling := <-exits
+				if !slices.Contains(restartSet, sibling.i) {
+					pending = append(pending, sibling)
+					continue
+				}
+				running[sibling.i] = false
+			}
+		}
+
+		if !s.backoff(ctx, stopCh, len(childRestarts[e.i])) {
+			continue
+		}
+		if s.beforeRestart != nil {
+			s.beforeRestart()
+		}
+		s.restart(func() {
+			for _, i := range restartSet {
+				if i == e.i || s.Children[i].Restart != RestartNever {
+					_ = start(i)
+				}
+			}
+		})
+	}
+}
+
+// restart calls fn unless the supervisor is stopping. It holds mu while doing
+// so such that [Supervisor.Stop] either prevents the restart or stops the
+// restarted children.
+func (s *Supervisor) restart(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.stopping {
+		fn()
+	}
+}
+
+// Stop prevents any further restarts then stops all children in reverse
+// order. Blocks until all [Runner.Stop] have returned normally, then returns
+// the first non-nil error (if any) from them.
+func (s *Supervisor) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.stopping {
+		s.stopping = true
+		if s.stopCh != nil {
+			close(s.stopCh)
+		}
+	}
+	s.mu.Unlock()
+
+	runners := make([]Runner, len(s.Children))
+	for i, c := range s.Children {
+		runners[i] = c.Runner
+	}
+
+	return stopAll(ctx, runners, StopReverse, 0)
+}
+
+func (s *Supervisor) init() (<-chan struct{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopCh == nil {
+		s.stopCh = make(chan struct{})
+	}
+
+	return s.stopCh, s.stopping
+}
+
+func (s *Supervisor) shouldRestart(ctx context.Context, stopCh <-chan struct{}, e exit) bool {
+	select {
+	case <-stopCh:
+		return false
+	case <-ctx.Done():
+		return false
+	default:
+	}
+
+	switch s.Children[e.i].Restart {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return e.err != nil
+	default:
+		return false
+	}
+}
+
+// restartSet returns the indexes of the children which must be restarted when
+// child i needs to be restarted.
+func (s *Supervisor) restartSet(i int) []int {
+	var set []int
+	switch s.Strategy {
+	case OneForAll:
+		i = 0
+	case RestForOne:
+	default:
+		return []int{i}
+	}
+	for ; i < len(s.Children); i++ {
+		set = append(set, i)
+	}
+
+	return set
+}
+
+// backoff waits before the nth restart of a child, returning false if the
+// supervisor was stopped or the passed context was canceled in the meantime.
+func (s *Supervisor) backoff(ctx context.Context, stopCh <-chan struct{}, n int) bool {
+	minBackoff := cmp.Or(s.MinBackoff, defaultMinBackoff)
+	maxBackoff := cmp.Or(s.MaxBackoff, defaultMaxBackoff)
+	delay := minBackoff
+	for ; n > 1 && delay < maxBackoff; n-- {
+		delay *= 2
+	}
+	delay = min(delay, max(maxBackoff, minBackoff))
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-stopCh:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func withinWindow(times []time.Time, now time.Time, window time.Duration) []time.Time {
+	if window <= 0 {
+		return times
+	}
+	for len(times) > 0 && now.Sub(times[0]) > window {
+		times = times[1:]
+	}
+
+	return times
+}
+
+// RestartIntensityError occurs when a [Supervisor] restarts its children more
+// than MaxRestarts times within Window. Err is the error (if any) returned by
+// the child whose exit exceeded the intensity.
+type RestartIntensityError struct {
+	MaxRestarts int
+	Window      time.Duration
+	Err         error
+}
+
+func (e *RestartIntensityError) Error() string {
+	msg := fmt.Sprintf("more than %d restarts", e.MaxRestarts)
+	if e.Window > 0 {
+		msg += fmt.Sprintf(" within %s", e.Window)
+	}
+	if e.Err != nil {
+		msg += fmt.Sprintf(": %v", e.Err)
+	}
+
+	return msg
+}
+
+func (e *RestartIntensityError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,358 @@
+package graceful_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wafer-bw/go-toolbox/graceful"
+)
+
+// restartable is a [graceful.Runner] which can be started repeatedly. Each
+// [restartable.Start] returns the next of exits until they are exhausted, then
+// blocks until [restartable.Stop] is called or the passed context is canceled.
+type restartable struct {
+	mu     sync.Mutex
+	exits  []error
+	starts []time.Time
+	stops  int
+	stopCh chan struct{}
+	onStop func()
+
+	// stopped is set when Stop is called while Start is not blocking so that
+	// the next Start returns immediately as required by [graceful.Runner].
+	stopped bool
+}
+
+func (r *restartable) Start(ctx context.Context) error {
+	r.mu.Lock()
+	r.starts = append(r.starts, time.Now())
+	if r.stopped {
+		r.stopped = false
+		r.mu.Unlock()
+		return nil
+	}
+	if len(r.exits) > 0 {
+		err := r.exits[0]
+		r.exits = r.exits[1:]
+		r.mu.Unlock()
+		return err
+	}
+	stopCh := make(chan struct{})
+	r.stopCh = stopCh
+	r.mu.Unlock()
+
+	select {
+	case <-stopCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *restartable) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stops++
+	if r.onStop != nil {
+		r.onStop()
+	}
+	if r.stopCh != nil {
+		close(r.stopCh)
+		r.stopCh = nil
+	} else {
+		r.stopped = true
+	}
+	return nil
+}
+
+func (r *restartable) Starts() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.starts)
+}
+
+func (r *restartable) Stops() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stops
+}
+
+func (r *restartable) Running() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stopCh != nil
+}
+
+// startSupervisor starts s in the background, returning a channel which
+// receives the result of [graceful.Supervisor.Start].
+func startSupervisor(t *testing.T, s *graceful.Supervisor) <-chan error {
+	t.Helper()
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.Start(t.Context()) }()
+	return errCh
+}
+
+func TestSupervisor_Start(t *testing.T) {
+	t.Parallel()
+
+	t.Run("restarts failed children one for one", func(t *testing.T) {
+		t.Parallel()
+
+		fail := errors.New("oh no")
+		a := &restartable{exits: []error{fail, fail}}
+		b := &restartable{}
+		s := &graceful.Supervisor{Children: []graceful.Child{
+			{Runner: a, Restart: graceful.RestartOnFailure},
+			{Runner: b, Restart: graceful.RestartOnFailure},
+		}}
+
+		errCh := startSupervisor(t, s)
+		require.Eventually(t, func() bool { return a.Running() && b.Running() }, time.Second, time.Millisecond)
+		require.Equal(t, 3, a.Starts())
+		require.Equal(t, 1, b.Starts())
+		require.Zero(t, b.Stops())
+
+		require.NoError(t, s.Stop(t.Context()))
+		require.NoError(t, <-errCh)
+	})
+
+	t.Run("does not restart children which exit normally on failure policy", func(t *testing.T) {
+		t.Parallel()
+
+		a := &restartable{exits: []error{nil}}
+		s := &graceful.Supervisor{Children: []graceful.Child{
+			{Runner: a, Restart: graceful.RestartOnFailure},
+		}}
+
+		require.NoError(t, <-startSupervisor(t, s))
+		require.Equal(t, 1, a.Starts())
+	})
+
+	t.Run("restarts children which exit normally on always policy", func(t *testing.T) {
+		t.Parallel()
+
+		a := &restartable{exits: []error{nil, nil}}
+		s := &graceful.Supervisor{Children: []graceful.Child{
+			{Runner: a, Restart: graceful.RestartAlways},
+		}}
+
+		errCh := startSupervisor(t, s)
+		require.Eventually(t, a.Running, time.Second, time.Millisecond)
+		require.Equal(t, 3, a.Starts())
+
+		require.NoError(t, s.Stop(t.Context()))
+		require.NoError(t, <-errCh)
+	})
+
+	t.Run("returns first error once all children exit on never policy", func(t *testing.T) {
+		t.Parallel()
+
+		fail := errors.New("oh no")
+		a := &restartable{exits: []error{fail}}
+		b := &restartable{exits: []error{nil}}
+		s := &graceful.Supervisor{Children: []graceful.Child{
+			{Runner: a, Restart: graceful.RestartNever},
+			{Runner: b, Restart: graceful.RestartNever},
+		}}
+
+		require.Equal(t, fail, <-startSupervisor(t, s))
+		require.Equal(t, 1, a.Starts())
+	})
+
+	t.Run("restarts all children one for all", func(t *testing.T) {
+		t.Parallel()
+
+		fail := errors.New("oh no")
+		a := &restartable{}
+		b := &restartable{exits: []error{fail}}
+		c := &restartable{}
+		s := &graceful.Supervisor{
+			Strategy: graceful.OneForAll,
+			Children: []graceful.Child{
+				{Runner: a, Restart: graceful.RestartAlways},
+				{Runner: b, Restart: graceful.RestartOnFailure},
+				{Runner: c, Restart: graceful.RestartNever},
+			},
+		}
+
+		errCh := startSupervisor(t, s)
+		require.Eventually(t, func() bool { return a.Starts() == 2 && b.Running() }, time.Second, time.Millisecond)
+		require.Equal(t, 1, a.Stops())
+		require.Equal(t, 2, b.Starts())
+		require.Equal(t, 1, c.Stops())
+		require.Equal(t, 1, c.Starts(), "never policy children are not restarted")
+
+		require.NoError(t, s.Stop(t.Context()))
+		require.NoError(t, <-errCh)
+	})
+
+	t.Run("restarts later children rest for one", func(t *testing.T) {
+		t.Parallel()
+
+		fail := errors.New("oh no")
+		a := &restartable{}
+		b := &restartable{exits: []error{fail}}
+		c := &restartable{}
+		s := &graceful.Supervisor{
+			Strategy: graceful.RestForOne,
+			Children: []graceful.Child{
+				{Runner: a, Restart: graceful.RestartAlways},
+				{Runner: b, Restart: graceful.RestartOnFailure},
+				{Runner: c, Restart: graceful.RestartAlways},
+			},
+		}
+
+		errCh := startSupervisor(t, s)
+		require.Eventually(t, func() bool { return c.Starts() == 2 && b.Running() }, time.Second, time.Millisecond)
+		require.Equal(t, 1, a.Starts())
+		require.Zero(t, a.Stops())
+		require.Equal(t, 2, b.Starts())
+		require.Equal(t, 1, c.Stops())
+
+		require.NoError(t, s.Stop(t.Context()))
+		require.NoError(t, <-errCh)
+	})
+
+	t.Run("returns an error when restart intensity is exceeded", func(t *testing.T) {
+		t.Parallel()
+
+		fail := errors.New("oh no")
+		a := &restartable{exits: []error{fail, fail, fail, fail}}
+		s := &graceful.Supervisor{
+			MaxRestarts: 2,
+			Window:      time.Minute,
+			Children:    []graceful.Child{{Runner: a, Restart: graceful.RestartOnFailure}},
+		}
+
+		err := <-startSupervisor(t, s)
+		require.Equal(t, &graceful.RestartIntensityError{MaxRestarts: 2, Window: time.Minute, Err: fail}, err)
+		require.ErrorIs(t, err, fail)
+		require.Equal(t, 3, a.Starts())
+	})
+
+	t.Run("backs off exponentially between restarts", func(t *testing.T) {
+		t.Parallel()
+
+		fail := errors.New("oh no")
+		a := &restartable{exits: []error{fail, fail}}
+		s := &graceful.Supervisor{
+			MinBackoff: 20 * time.Millisecond,
+			MaxBackoff: time.Second,
+			Children:   []graceful.Child{{Runner: a, Restart: graceful.RestartOnFailure}},
+		}
+
+		errCh := startSupervisor(t, s)
+		require.Eventually(t, a.Running, time.Second, time.Millisecond)
+		require.NoError(t, s.Stop(t.Context()))
+		require.NoError(t, <-errCh)
+
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		require.GreaterOrEqual(t, a.starts[1].Sub(a.starts[0]), 20*time.Millisecond)
+		require.GreaterOrEqual(t, a.starts[2].Sub(a.starts[1]), 40*time.Millisecond)
+	})
+
+	t.Run("backs off by default between restarts", func(t *testing.T) {
+		t.Parallel()
+
+		a := &restartable{exits: []error{nil, nil}}
+		s := &graceful.Supervisor{Children: []graceful.Child{
+			{Runner: a, Restart: graceful.RestartAlways},
+		}}
+
+		errCh := startSupervisor(t, s)
+		require.Eventually(t, a.Running, time.Second, time.Millisecond)
+		require.NoError(t, s.Stop(t.Context()))
+		require.NoError(t, <-errCh)
+
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		require.Len(t, a.starts, 3)
+		require.GreaterOrEqual(t, a.starts[1].Sub(a.starts[0]), 100*time.Millisecond)
+		require.GreaterOrEqual(t, a.starts[2].Sub(a.starts[1]), 200*time.Millisecond)
+	})
+
+	t.Run("does not restart children once stopped", func(t *testing.T) {
+		t.Parallel()
+
+		a := &restartable{}
+		s := &graceful.Supervisor{Children: []graceful.Child{
+			{Runner: a, Restart: graceful.RestartAlways},
+		}}
+
+		errCh := startSupervisor(t, s)
+		require.Eventually(t, a.Running, time.Second, time.Millisecond)
+		require.NoError(t, s.Stop(t.Context()))
+		require.NoError(t, <-errCh)
+		require.Equal(t, 1, a.Starts())
+	})
+
+	t.Run("does not panic when runners are nil", func(t *testing.T) {
+		t.Parallel()
+
+		s := &graceful.Supervisor{Children: []graceful.Child{{}, {}}}
+		require.NotPanics(t, func() {
+			require.NoError(t, s.Start(t.Context()))
+		})
+	})
+}
+
+func TestSupervisor_Stop(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stops children in reverse order", func(t *testing.T) {
+		t.Parallel()
+
+		rec := &recorder{}
+		a := &restartable{onStop: func() { rec.record("a") }}
+		b := &restartable{onStop: func() { rec.record("b") }}
+		s := &graceful.Supervisor{Children: []graceful.Child{{Runner: a}, {Runner: b}}}
+
+		require.NoError(t, s.Stop(t.Context()))
+		require.Equal(t, []string{"b", "a"}, rec.Events())
+	})
+
+	t.Run("returns first stop error encountered", func(t *testing.T) {
+		t.Parallel()
+
+		stopErr := errors.New("stop failed")
+		s := &graceful.Supervisor{Children: []graceful.Child{
+			{Runner: graceful.RunnerType{StopFunc: func(ctx context.Context) error { return stopErr }}},
+			{Runner: graceful.RunnerType{}},
+		}}
+
+		require.Equal(t, stopErr, s.Stop(t.Context()))
+	})
+
+	t.Run("prevents a restart once the backoff has elapsed", func(t *testing.T) {
+		t.Parallel()
+
+		a := &restartable{exits: []error{errors.New("oh no")}}
+		s := &graceful.Supervisor{Children: []graceful.Child{{Runner: a, Restart: graceful.RestartAlways}}}
+		graceful.SetBeforeRestart(s, func() { _ = s.Stop(t.Context()) })
+
+		select {
+		case err := <-startSupervisor(t, s):
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			require.FailNow(t, "supervisor did not return")
+		}
+		require.Equal(t, 1, a.Starts())
+	})
+}
+
+func TestRestartIntensityError_Error(t *testing.T) {
+	t.Parallel()
+
+	err := &graceful.RestartIntensityError{MaxRestarts: 3, Window: time.Second, Err: errors.New("oh no")}
+	require.Equal(t, "more than 3 restarts within 1s: oh no", err.Error())
+
+	err = &graceful.RestartIntensityError{MaxRestarts: 3}
+	require.Equal(t, "more than 3 restarts", err.Error())
+}
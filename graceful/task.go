@@ -0,0 +1,132 @@
+package graceful
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// WithCompletion makes [Run] initiate stopping once every [Runner] in the tree
+// marked via [Task] has completed, that is its [Runner.Start] returned nil,
+// such as for a batch binary which runs migrations then exits. Run then stops
+// the tree as usual, calling every [Runner.Stop], and returns nil unless
+// stopping them fails.
+//
+// Tasks are found when the tree is started, so tasks added to a
+// [DynamicGroup] later are not waited on. If no runner in the tree is marked
+// via Task, the tree itself is treated as a task.
+func WithCompletion() RunOption {
+	return func(cfg *RunConfig) {
+		cfg.completion = true
+	}
+}
+
+// Task returns a [Runner] which wraps r, marking it as a task which completes
+// once its [Runner.Start] returns nil. See [WithCompletion].
+func Task(r Runner) Runner {
+	return task{runner: r}
+}
+
+type task struct {
+	runner Runner
+}
+
+func (t task) Start(ctx context.Context) error { return t.runner.Start(ctx) }
+func (t task) Stop(ctx context.Context) error  { return t.runner.Stop(ctx) }
+func (t task) Unwrap() Runner                  { return t.runner }
+
+// Service returns a [Runner] which wraps r, marking it as a service which runs
+// until it is stopped. If its [Runner.Start] returns nil before its
+// [Runner.Stop] is called or the context passed to Start is canceled, an
+// [EarlyExitError] is returned instead, initiating stopping when run via
+// [Run].
+func Service(r Runner) Runner {
+	return &service{runner: r}
+}
+
+type service struct {
+	runner Runner
+	// stopped is set by Stop and consumed by the Start which it stops, so that
+	// the service can be started again (e.g. by a [Supervisor]).
+	stopped atomic.Bool
+}
+
+func (s *service) Unwrap() Runner { return s.runner }
+
+func (s *service) Start(ctx context.Context) error {
+	err := s.runner.Start(ctx)
+	stopped := s.stopped.Swap(false)
+	if err == nil && ctx.Err() == nil && !stopped {
+		return &EarlyExitError{}
+	}
+
+	return err
+}
+
+func (s *service) Stop(ctx context.Context) error {
+	s.stopped.Store(true)
+	return s.runner.Stop(ctx)
+}
+
+// EarlyExitError occurs when the [Runner.Start] of a [Runner] marked via
+// [Service] returns nil before it was stopped.
+type EarlyExitError struct{}
+
+func (e *EarlyExitError) Error() string {
+	return "exited before being stopped"
+}
+
+// completion tracks the tasks of a tree started via [Run] with
+// [WithCompletion].
+type completion struct {
+	mu      sync.Mutex
+	pending map[string]bool
+	doneCh  chan struct{}
+}
+
+// newCompletion returns a completion which waits for the tasks in the tree
+// rooted at r, or r itself if it has none.
+func newCompletion(ctx context.Context, r Runner) *completion {
+	c := &completion{pending: map[string]bool{}, doneCh: make(chan struct{})}
+	walk(ctx, r, func(ctx context.Context, r Runner) {
+		if _, ok := as[task](r); ok {
+			c.pending[path(ctx)] = true
+		}
+	})
+	if len(c.pending) == 0 {
+		c.pending[path(ctx)] = true
+	}
+
+	return c
+}
+
+// complete the task whose methods were passed ctx (if any), closing the
+// channel returned by [completion.done] once no tasks are pending.
+func complete(ctx context.Context) {
+	state, ok := getRunState(ctx)
+	if !ok || state.completion == nil {
+		return
+	}
+
+	c := state.completion
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.pending[path(ctx)] {
+		return
+	}
+	delete(c.pending, path(ctx))
+	if len(c.pending) == 0 {
+		close(c.doneCh)
+	}
+}
+
+// done returns a channel which is closed once all tasks have completed. It
+// never closes if c is nil.
+func (c *completion) done() <-chan struct{} {
+	if c == nil {
+		return nil
+	}
+
+	return c.doneCh
+}
@@ -0,0 +1,146 @@
+package graceful_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wafer-bw/go-toolbox/graceful"
+)
+
+func TestWithCompletion(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns once every task completes & stops the rest of the tree", func(t *testing.T) {
+		t.Parallel()
+
+		doneCh, unblockCh := make(chan struct{}), make(chan struct{})
+		close(doneCh)
+		stoppedCh := make(chan struct{})
+		completedCh := make(chan struct{}, 2)
+		task := func(waitCh <-chan struct{}) graceful.Runner {
+			return graceful.Task(graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error {
+					<-waitCh
+					completedCh <- struct{}{}
+					return nil
+				},
+			})
+		}
+		metrics := graceful.Service(graceful.RunnerType{
+			StartFunc: func(ctx context.Context) error {
+				<-stoppedCh
+				return nil
+			},
+			StopFunc: func(ctx context.Context) error {
+				close(stoppedCh)
+				return nil
+			},
+		})
+		g := graceful.Group{
+			graceful.Named("migrate", task(doneCh)),
+			graceful.Named("seed", task(unblockCh)),
+			graceful.Named("metrics", metrics),
+		}
+
+		ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+		defer cancel()
+		errCh := make(chan error, 1)
+		go func() { errCh <- graceful.Run(ctx, g, graceful.WithCompletion()) }()
+
+		time.Sleep(10 * time.Millisecond)
+		require.Empty(t, errCh)
+
+		close(unblockCh)
+		require.NoError(t, <-errCh)
+		require.Len(t, completedCh, 2)
+		_, open := <-stoppedCh
+		require.False(t, open)
+		require.NoError(t, ctx.Err())
+	})
+
+	t.Run("treats the tree as a task when none are marked", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+		defer cancel()
+		err := graceful.Run(ctx, graceful.Group{graceful.RunnerType{}, graceful.RunnerType{}}, graceful.WithCompletion())
+		require.NoError(t, err)
+		require.NoError(t, ctx.Err())
+	})
+
+	t.Run("returns the error of a failed task", func(t *testing.T) {
+		t.Parallel()
+
+		startErr := errors.New("migration failed")
+		g := graceful.Group{
+			graceful.Named("migrate", graceful.Task(graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error { return startErr },
+			})),
+		}
+
+		err := graceful.Run(t.Context(), g, graceful.WithCompletion())
+		require.ErrorIs(t, err, startErr)
+	})
+}
+
+func TestService(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stops the tree when a service exits early", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+		defer cancel()
+		g := graceful.Group{
+			graceful.Named("api", graceful.Service(graceful.RunnerType{})),
+			graceful.RunnerType{
+				StartFunc: func(ctx context.Context) error {
+					<-ctx.Done()
+					return nil
+				},
+			},
+		}
+
+		err := graceful.Run(ctx, g)
+		reason := &graceful.ShutdownReason{}
+		require.ErrorAs(t, err, &reason)
+		require.Equal(t, "api", reason.Runner)
+		require.ErrorAs(t, err, new(*graceful.EarlyExitError))
+		require.NoError(t, ctx.Err())
+	})
+
+	t.Run("does not return an error once stopped", func(t *testing.T) {
+		t.Parallel()
+
+		r := graceful.Service(graceful.RunnerType{})
+		require.NoError(t, r.Stop(t.Context()))
+		require.NoError(t, r.Start(t.Context()))
+	})
+
+	t.Run("returns an error when it exits early once restarted", func(t *testing.T) {
+		t.Parallel()
+
+		r := graceful.Service(graceful.RunnerType{})
+		require.NoError(t, r.Stop(t.Context()))
+		require.NoError(t, r.Start(t.Context()))
+		require.ErrorAs(t, r.Start(t.Context()), new(*graceful.EarlyExitError))
+	})
+
+	t.Run("does not return an error once its context is canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		r := graceful.Service(graceful.RunnerType{})
+		require.NoError(t, r.Start(ctx))
+	})
+}
+
+func TestEarlyExitError_Error(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "exited before being stopped", (&graceful.EarlyExitError{}).Error())
+}
@@ -0,0 +1,496 @@
+package graceful
+
+import (
+	"context"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// tree is implemented by the runners in this package which start & stop other
+// runners on their behalf.
+type tree interface {
+	Runner
+	// children returns the runners started & stopped on behalf of the tree.
+	children() []child
+}
+
+// child is a [Runner] within a [tree] along with its name within the tree.
+type child struct {
+	name   string
+	runner Runner
+}
+
+func (g Group) children() []child        { return childrenOf(g) }
+func (g OrderedGroup) children() []child { return childrenOf(g.Group) }
+func (s Stages) children() []child       { return childrenOf(s) }
+
+func (g Graph) children() []child {
+	children := make([]child, 0, len(g))
+	for _, n := range g {
+		if n.Runner != nil {
+			children = append(children, child{name: n.Name, runner: n.Runner})
+		}
+	}
+
+	return children
+}
+
+// childrenOf returns the non-nil runners as children named by their index
+// unless they have a name.
+func childrenOf(runners []Runner) []child {
+	children := make([]child, 0, len(runners))
+	for i, r := range runners {
+		if r != nil {
+			children = append(children, child{name: nameOf(r, i), runner: r})
+		}
+	}
+
+	return children
+}
+
+// walk calls fn for r and every [Runner] in the tree rooted at it, in the order
+// they were declared, passing the context for the methods of each.
+func walk(ctx context.Context, r Runner, fn func(ctx context.Context, r Runner)) {
+	fn(ctx, r)
+	if t, ok := as[tree](r); ok {
+		for _, c := range t.children() {
+			walk(childContext(ctx, c.name), c.runner, fn)
+		}
+	}
+}
+
+// as finds the first [Runner] in the chain of runners wrapped by r which is of
+// type T. A wrapping runner exposes the runner it wraps via an Unwrap() Runner
+// method.
+func as[T any](r Runner) (T, bool) {
+	for r != nil {
+		if t, ok := r.(T); ok {
+			return t, true
+		}
+		u, ok := r.(interface{ Unwrap() Runner })
+		if !ok {
+			break
+		}
+		r = u.Unwrap()
+	}
+
+	var zero T
+	return zero, false
+}
+
+// nameOf returns the name of r if it has one, otherwise its index i within its
+// parent.
+func nameOf(r Runner, i int) string {
+	if n, ok := as[interface{ Name() string }](r); ok {
+		return n.Name()
+	}
+
+	return strconv.Itoa(i)
+}
+
+type runStateKey struct{}
+
+// runState is shared throughout a tree of runners started by [Run].
+type runState struct {
+	cfg *RunConfig
+
+	mu   sync.Mutex
+	errs []error
+
+	// reasonCh receives the first [ShutdownReason] due to a [Runner.Start]
+	// failing.
+	reasonCh chan *ShutdownReason
+
+	// completion tracks tasks when [WithCompletion] is used.
+	completion *completion
+
+	// reason is why stopping was initiated, once it has been.
+	reason *ShutdownReason
+}
+
+// getRunState returns the state of the [Run] which ctx descends from, if any.
+func getRunState(ctx context.Context) (*runState, bool) {
+	state, ok := ctx.Value(runStateKey{}).(*runState)
+	return state, ok
+}
+
+func (s *runState) errors() []error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]error(nil), s.errs...)
+}
+
+// setReason records why stopping was initiated.
+func (s *runState) setReason(reason *ShutdownReason) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reason = reason
+}
+
+// stopCause returns the cause with which to cancel the context of a runner
+// which was stopped before it was started: the [ShutdownReason] if stopping of
+// the [Run] which ctx descends from was initiated, otherwise err.
+func stopCause(ctx context.Context, err *TransitionError) error {
+	state, ok := getRunState(ctx)
+	if !ok {
+		return err
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if state.reason == nil {
+		return err
+	}
+
+	return state.reason
+}
+
+// fail initiates stopping because the [Runner.Start] of the runner whose
+// methods were passed ctx returned err, unless its failure is contained by a
+// parent (see [contain]) or stopping has already been initiated.
+func fail(ctx context.Context, err error) {
+	state, ok := getRunState(ctx)
+	if !ok || err == nil || ctx.Err() != nil || ctx.Value(containedKey{}) != nil {
+		return
+	}
+
+	select {
+	case state.reasonCh <- &ShutdownReason{Runner: path(ctx), Err: err}:
+	default:
+	}
+}
+
+type containedKey struct{}
+
+// contain returns a context for the children of a runner which handles their
+// failures itself, such that they do not initiate stopping.
+func contain(ctx context.Context) context.Context {
+	return context.WithValue(ctx, containedKey{}, struct{}{})
+}
+
+type pathKey struct{}
+
+// path returns the name of the runner whose methods were passed ctx.
+func path(ctx context.Context) string {
+	p, _ := ctx.Value(pathKey{}).(string)
+	return p
+}
+
+// childContext returns a context for the child named name of the runner whose
+// methods were passed ctx.
+func childContext(ctx context.Context, name string) context.Context {
+	switch p := path(ctx); {
+	case name == "":
+		return ctx
+	case p != "":
+		name = p + "/" + name
+	}
+
+	return context.WithValue(ctx, pathKey{}, name)
+}
+
+// report err from op of the runner whose methods were passed ctx if [Run] was
+// configured to collect errors.
+func report(ctx context.Context, op string, err error) {
+	state, ok := getRunState(ctx)
+	if !ok || !state.cfg.joinErrors || err == nil {
+		return
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.errs = append(state.errs, &RunnerError{Name: path(ctx), Op: op, Err: err})
+}
+
+// emit an [Event] of kind for the runner whose methods were passed ctx if [Run]
+// was configured with an [EventHook].
+func emit(ctx context.Context, kind EventKind, since time.Time, err error) {
+	state, ok := getRunState(ctx)
+	if !ok || state.cfg.eventHook == nil {
+		return
+	}
+
+	state.cfg.eventHook(Event{
+		Name:     path(ctx),
+		Kind:     kind,
+		Duration: time.Since(since),
+		Err:      err,
+	})
+}
+
+// withReady returns a function which calls r.Start and a channel which will
+// receive nil once r is ready or the error which prevented it from becoming
+// ready. The name of r within its parent is name.
+func withReady(ctx context.Context, name string, r Runner) (func() error, <-chan error) {
+	ctx = childContext(ctx, name)
+	_, isTree := as[tree](r)
+	m := monitor(ctx)
+	readyCh := make(chan error, 1)
+	once, startedOnce := &sync.Once{}, &sync.Once{}
+	var began time.Time
+	started := func() {
+		startedOnce.Do(func() {
+			m.ready(path(ctx))
+			emit(ctx, EventStarted, began, nil)
+		})
+	}
+	setReady := func(err error) {
+		once.Do(func() {
+			if err == nil {
+				started()
+			}
+			readyCh <- err
+		})
+	}
+
+	readier, isReadier := as[Readier](r)
+	switch rt := readier.(type) {
+	case RunnerType:
+		isReadier = rt.ReadyFunc != nil
+	case *RunnerType:
+		isReadier = rt != nil && rt.ReadyFunc != nil
+	}
+	_, marksReady := as[readyMarker](r)
+	awaitsMark := !isReadier && (isTree || marksReady)
+	markReady := func() {}
+	if awaitsMark {
+		markReady = func() { setReady(nil) }
+	}
+	startCtx := context.WithValue(ctx, readyKey{}, markReady)
+
+	// the start is recorded immediately rather than once start is called so
+	// that a stop which happens in between is not mistaken for a previous one.
+	stoppedErr, beginErr := m.beginStart(path(ctx))
+	start := func() error {
+		err := beginErr
+		if stoppedErr == nil && err == nil {
+			stoppedErr = m.preempted(path(ctx))
+		}
+		if stoppedErr != nil {
+			stoppedCtx, cancel := context.WithCancelCause(startCtx)
+			cancel(stopCause(ctx, stoppedErr))
+			startCtx = stoppedCtx
+		}
+		began = time.Now()
+		if err != nil {
+			emit(ctx, EventStartFailed, began, err)
+			report(ctx, "start", err)
+			fail(ctx, err)
+			setReady(err)
+			return err
+		}
+
+		emit(ctx, EventStarting, began, nil)
+		switch {
+		case isReadier:
+			go func() {
+				err := protect(func() error { return readier.Ready(ctx) })
+				if ctx.Err() == nil {
+					report(ctx, "ready", err)
+					fail(ctx, err)
+				}
+				setReady(err)
+			}()
+		case !awaitsMark:
+			// r is reported as started separately so that a Start which fails
+			// at once is not reported as having started.
+			once.Do(func() { readyCh <- nil })
+			go started()
+		}
+
+		err = protect(func() error { return r.Start(startCtx) })
+		if err != nil {
+			startedOnce.Do(func() {})
+		} else {
+			started()
+		}
+		m.endStart(path(ctx), err)
+		if err != nil {
+			emit(ctx, EventStartFailed, began, err)
+		}
+		if !isTree {
+			report(ctx, "start", err)
+		}
+		fail(startCtx, err)
+		if err == nil {
+			complete(ctx)
+		}
+		setReady(err)
+
+		return err
+	}
+
+	return start, readyCh
+}
+
+// tee returns n channels which each receive the value received from ch.
+func tee(ch <-chan error, n int) []<-chan error {
+	chs := make([]chan error, n)
+	outs := make([]<-chan error, n)
+	for i := range chs {
+		chs[i] = make(chan error, 1)
+		outs[i] = chs[i]
+	}
+	go func() {
+		err := <-ch
+		for _, c := range chs {
+			c <- err
+		}
+	}()
+
+	return outs
+}
+
+// withCancelOf returns a context which carries the values of values but is
+// canceled when ctx is and shares its deadline (if any).
+func withCancelOf(values, ctx context.Context) (context.Context, context.CancelFunc) {
+	merged := context.WithoutCancel(values)
+	var cancel context.CancelFunc
+	if deadline, ok := ctx.Deadline(); ok {
+		merged, cancel = context.WithDeadline(merged, deadline)
+	} else {
+		merged, cancel = context.WithCancel(merged)
+	}
+	stop := context.AfterFunc(ctx, cancel)
+
+	return merged, func() {
+		stop()
+		cancel()
+	}
+}
+
+// protect calls fn, recovering a panic from it as a [PanicError].
+func protect(fn func() error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+
+	return fn()
+}
+
+// stopAll stops each of runners in order. Runners are named by their index
+// unless they have a name. When stopping in parallel, at most limit runners are
+// stopped at once unless limit is less than or equal to 0.
+//
+// When stopping in series, if [Run] was configured via [WithStopBudget] and ctx
+// has a deadline, each runner is allotted an equal share of the time remaining
+// for the runners yet to be stopped.
+func stopAll(ctx context.Context, runners []Runner, order StopOrder, limit int) error {
+	if order == StopParallel {
+		eg := new(errgroup.Group)
+		if limit > 0 {
+			eg.SetLimit(limit)
+		}
+		for i, r := range runners {
+			if r == nil {
+				continue
+			}
+			eg.Go(func() error { return stopRunner(ctx, nameOf(r, i), r, 0) })
+		}
+
+		return eg.Wait()
+	}
+
+	indexes := make([]int, 0, len(runners))
+	for i, r := range runners {
+		if r != nil {
+			indexes = append(indexes, i)
+		}
+	}
+	if order == StopReverse {
+		slices.Reverse(indexes)
+	}
+
+	state, ok := getRunState(ctx)
+	deadline, hasDeadline := ctx.Deadline()
+	budgeted := ok && state.cfg.stopBudget && hasDeadline
+
+	var firstErr error
+	for n, i := range indexes {
+		var allotted time.Duration
+		if budgeted {
+			allotted = time.Until(deadline) / time.Duration(len(indexes)-n)
+		}
+		if err := stopRunner(ctx, nameOf(runners[i], i), runners[i], allotted); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// stopRunner calls r.Stop. The name of r within its parent is name. If
+// allotted is positive, r.Stop is given at most that long to return before a
+// [StopTimeoutError] is returned.
+func stopRunner(ctx context.Context, name string, r Runner, allotted time.Duration) error {
+	ctx = childContext(ctx, name)
+	m := monitor(ctx)
+	if m.beginStop(path(ctx)) {
+		return nil
+	}
+
+	var timeoutErr *StopTimeoutError
+	if allotted > 0 {
+		timeoutErr = &StopTimeoutError{Name: path(ctx), Allotted: allotted}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, allotted, timeoutErr)
+		defer cancel()
+	}
+	began := time.Now()
+	emit(ctx, EventStopping, began, nil)
+
+	timeoutOnce := &sync.Once{}
+	returned := &atomic.Bool{}
+	emitTimeout := func() {
+		timeoutOnce.Do(func() { emit(ctx, EventStopTimeout, began, ctx.Err()) })
+	}
+	state, ok := getRunState(ctx)
+	watchTimeout := ok && state.cfg.eventHook != nil && ctx.Err() == nil
+	if watchTimeout {
+		doneCh := make(chan struct{})
+		defer close(doneCh)
+		go func() {
+			select {
+			case <-doneCh:
+			case <-ctx.Done():
+				// The stop context may be canceled after Stop has returned, in
+				// which case it didn't time out.
+				if !returned.Load() {
+					emitTimeout()
+				}
+			}
+		}()
+	}
+
+	err := protect(func() error { return r.Stop(ctx) })
+	timedOut := ctx.Err() != nil
+	returned.Store(true)
+	if watchTimeout && timedOut {
+		emitTimeout()
+	}
+	if timeoutErr != nil && context.Cause(ctx) == error(timeoutErr) {
+		timeoutErr.Err = err
+		err = timeoutErr
+	}
+	m.endStop(path(ctx), err)
+	if err != nil {
+		emit(ctx, EventStopFailed, began, err)
+	} else {
+		emit(ctx, EventStopped, began, nil)
+	}
+	if _, isTree := as[tree](r); !isTree {
+		report(ctx, "stop", err)
+	}
+
+	return err
+}
@@ -0,0 +1,120 @@
+package memkv
+
+import "time"
+
+// Counter is a set of keyed counters backed by a [Store].
+//
+// Each key's counter expires ttl after it was first incremented or decremented,
+// at which point it restarts from zero. Expired counters are reclaimed when the
+// underlying [Store] is at capacity, keeping memory bounded for large key sets
+// such as client IPs.
+type Counter[K comparable] struct {
+	ttl time.Duration
+	kv  keyed[K, count]
+}
+
+type count struct {
+	n         int64
+	expiresAt time.Time
+}
+
+// NewCounter creates a new instance of [Counter] which tracks at most capacity
+// keys.
+//
+//   - A capacity of zero means there is no limit to the number of keys.
+//   - If the capacity is less than 0, it will be set to 0.
+//   - A ttl less than or equal to 0 means counters never expire.
+func NewCounter[K comparable](capacity int, ttl time.Duration) *Counter[K] {
+	return &Counter[K]{
+		ttl: ttl,
+		kv:  newKeyed[K, count](capacity),
+	}
+}
+
+// Incr increments the counter for key by one and returns its new value.
+//
+// An [AtCapacityError] is returned if key is not yet tracked and no room could
+// be reclaimed for it.
+func (c *Counter[K]) Incr(key K) (int64, error) {
+	return c.Add(key, 1)
+}
+
+// Decr decrements the counter for key by one and returns its new value.
+//
+// An [AtCapacityError] is returned if key is not yet tracked and no room could
+// be reclaimed for it.
+func (c *Counter[K]) Decr(key K) (int64, error) {
+	return c.Add(key, -1)
+}
+
+// Add delta to the counter for key and return its new value.
+//
+// An [AtCapacityError] is returned if key is not yet tracked and no room could
+// be reclaimed for it.
+func (c *Counter[K]) Add(key K, delta int64) (int64, error) {
+	var n int64
+	err := c.kv.update(key, func(v count, ok bool, now time.Time) count {
+		if !ok || c.expired(v, now) {
+			v = count{}
+			if c.ttl > 0 {
+				v.expiresAt = now.Add(c.ttl)
+			}
+		}
+		v.n += delta
+		n = v.n
+		return v
+	}, c.expiresAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// Get the value of the counter for key if it exists and has not expired.
+func (c *Counter[K]) Get(key K) (int64, bool) {
+	c.kv.mu.Lock()
+	defer c.kv.mu.Unlock()
+
+	v, ok := c.kv.store.Get(key)
+	if !ok || c.expired(v, c.kv.now()) {
+		return 0, false
+	}
+
+	return v.n, true
+}
+
+// TTL returns the time remaining until the counter for key expires. A duration
+// of zero is returned if the counter does not exist, has expired, or never
+// expires.
+func (c *Counter[K]) TTL(key K) time.Duration {
+	c.kv.mu.Lock()
+	defer c.kv.mu.Unlock()
+
+	v, ok := c.kv.store.Get(key)
+	if !ok || v.expiresAt.IsZero() {
+		return 0
+	}
+
+	return max(v.expiresAt.Sub(c.kv.now()), 0)
+}
+
+// Delete the counters for the provided keys.
+func (c *Counter[K]) Delete(keys ...K) {
+	c.kv.store.Delete(keys...)
+}
+
+// Len returns the number of keys currently tracked, including any which have
+// expired but have not yet been reclaimed.
+func (c *Counter[K]) Len() int {
+	return c.kv.store.Len()
+}
+
+func (c *Counter[K]) expired(v count, now time.Time) bool {
+	return !v.expiresAt.IsZero() && !now.Before(v.expiresAt)
+}
+
+// expiresAt returns when v expires or false if it never does.
+func (c *Counter[K]) expiresAt(v count) (time.Time, bool) {
+	return v.expiresAt, !v.expiresAt.IsZero()
+}
@@ -0,0 +1,193 @@
+package memkv_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wafer-bw/go-toolbox/memkv"
+)
+
+func TestCounter_Incr(t *testing.T) {
+	t.Parallel()
+
+	t.Run("increments the counter", func(t *testing.T) {
+		t.Parallel()
+
+		counter := memkv.NewCounter[string](0, 0)
+		for _, want := range []int64{1, 2, 3} {
+			n, err := counter.Incr("key")
+			require.NoError(t, err)
+			require.Equal(t, want, n)
+		}
+	})
+
+	t.Run("restarts the counter once it expires", func(t *testing.T) {
+		t.Parallel()
+
+		clk := newClock()
+		counter := memkv.NewCounter[string](0, time.Second)
+		counter.SetNow(clk.Now)
+
+		_, err := counter.Incr("key")
+		require.NoError(t, err)
+
+		clk.Advance(500 * time.Millisecond)
+		n, err := counter.Incr("key")
+		require.NoError(t, err)
+		require.Equal(t, int64(2), n)
+
+		clk.Advance(500 * time.Millisecond)
+		n, err = counter.Incr("key")
+		require.NoError(t, err)
+		require.Equal(t, int64(1), n)
+	})
+
+	t.Run("reclaims expired counters when at capacity", func(t *testing.T) {
+		t.Parallel()
+
+		clk := newClock()
+		counter := memkv.NewCounter[string](1, time.Second)
+		counter.SetNow(clk.Now)
+
+		_, err := counter.Incr("key1")
+		require.NoError(t, err)
+
+		clk.Advance(time.Second)
+		n, err := counter.Incr("key2")
+		require.NoError(t, err)
+		require.Equal(t, int64(1), n)
+		require.Equal(t, 1, counter.Len())
+	})
+
+	t.Run("returns an error when at capacity and nothing can be reclaimed", func(t *testing.T) {
+		t.Parallel()
+
+		counter := memkv.NewCounter[string](1, 0)
+
+		_, err := counter.Incr("key1")
+		require.NoError(t, err)
+
+		_, err = counter.Incr("key2")
+		require.IsType(t, &memkv.AtCapacityError{}, err)
+	})
+}
+
+func TestCounter_Decr(t *testing.T) {
+	t.Parallel()
+
+	t.Run("decrements the counter", func(t *testing.T) {
+		t.Parallel()
+
+		counter := memkv.NewCounter[string](0, 0)
+		for _, want := range []int64{-1, -2} {
+			n, err := counter.Decr("key")
+			require.NoError(t, err)
+			require.Equal(t, want, n)
+		}
+	})
+}
+
+func TestCounter_Add(t *testing.T) {
+	t.Parallel()
+
+	t.Run("adds delta to the counter", func(t *testing.T) {
+		t.Parallel()
+
+		counter := memkv.NewCounter[string](0, 0)
+
+		n, err := counter.Add("key", 5)
+		require.NoError(t, err)
+		require.Equal(t, int64(5), n)
+
+		n, err = counter.Add("key", -3)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), n)
+	})
+}
+
+func TestCounter_Get(t *testing.T) {
+	t.Parallel()
+
+	t.Run("gets the counter value", func(t *testing.T) {
+		t.Parallel()
+
+		counter := memkv.NewCounter[string](0, 0)
+		_, err := counter.Incr("key")
+		require.NoError(t, err)
+
+		n, ok := counter.Get("key")
+		require.True(t, ok)
+		require.Equal(t, int64(1), n)
+	})
+
+	t.Run("returns false when the counter does not exist", func(t *testing.T) {
+		t.Parallel()
+
+		counter := memkv.NewCounter[string](0, 0)
+		_, ok := counter.Get("key")
+		require.False(t, ok)
+	})
+
+	t.Run("returns false when the counter has expired", func(t *testing.T) {
+		t.Parallel()
+
+		clk := newClock()
+		counter := memkv.NewCounter[string](0, time.Second)
+		counter.SetNow(clk.Now)
+
+		_, err := counter.Incr("key")
+		require.NoError(t, err)
+
+		clk.Advance(time.Second)
+		_, ok := counter.Get("key")
+		require.False(t, ok)
+	})
+}
+
+func TestCounter_TTL(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns the time remaining until expiry", func(t *testing.T) {
+		t.Parallel()
+
+		clk := newClock()
+		counter := memkv.NewCounter[string](0, time.Second)
+		counter.SetNow(clk.Now)
+
+		_, err := counter.Incr("key")
+		require.NoError(t, err)
+
+		clk.Advance(300 * time.Millisecond)
+		require.Equal(t, 700*time.Millisecond, counter.TTL("key"))
+
+		clk.Advance(time.Second)
+		require.Zero(t, counter.TTL("key"))
+	})
+
+	t.Run("returns zero when counters never expire", func(t *testing.T) {
+		t.Parallel()
+
+		counter := memkv.NewCounter[string](0, 0)
+		_, err := counter.Incr("key")
+		require.NoError(t, err)
+		require.Zero(t, counter.TTL("key"))
+	})
+}
+
+func TestCounter_Delete(t *testing.T) {
+	t.Parallel()
+
+	t.Run("deletes the counters", func(t *testing.T) {
+		t.Parallel()
+
+		counter := memkv.NewCounter[string](0, 0)
+		_, err := counter.Incr("key1")
+		require.NoError(t, err)
+		_, err = counter.Incr("key2")
+		require.NoError(t, err)
+
+		counter.Delete("key1", "key2")
+		require.Zero(t, counter.Len())
+	})
+}
@@ -0,0 +1,292 @@
+package memkv
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// TokenBucket is a keyed token bucket rate limiter backed by a [Store].
+//
+// Each key has its own bucket which holds up to burst tokens and regains one
+// token every interval. Buckets which have fully refilled are indistinguishable
+// from new buckets and are reclaimed when the underlying [Store] is at
+// capacity, keeping memory bounded for large key sets such as client IPs.
+type TokenBucket[K comparable] struct {
+	burst    int
+	interval time.Duration
+	kv       keyed[K, bucket]
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket creates a new instance of [TokenBucket] which tracks at most
+// capacity keys.
+//
+//   - A capacity of zero means there is no limit to the number of keys.
+//   - If the capacity is less than 0, it will be set to 0.
+//   - If burst is less than 1, it will be set to 1.
+//   - An interval less than or equal to 0 means buckets are always full.
+func NewTokenBucket[K comparable](capacity int, burst int, interval time.Duration) *TokenBucket[K] {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &TokenBucket[K]{
+		burst:    burst,
+		interval: interval,
+		kv:       newKeyed[K, bucket](capacity),
+	}
+}
+
+// Allow reports whether a single event for key may happen now.
+//
+// An [AtCapacityError] is returned if key is not yet tracked and no room could
+// be reclaimed for it.
+func (l *TokenBucket[K]) Allow(key K) (bool, error) {
+	return l.AllowN(key, 1)
+}
+
+// AllowN reports whether n events for key may happen now. Tokens are only
+// consumed when the events are allowed. An n less than 1 is never allowed.
+//
+// An [AtCapacityError] is returned if key is not yet tracked and no room could
+// be reclaimed for it.
+func (l *TokenBucket[K]) AllowN(key K, n int) (bool, error) {
+	if n < 1 {
+		return false, nil
+	}
+
+	var allowed bool
+	err := l.kv.update(key, func(b bucket, ok bool, now time.Time) bucket {
+		if !ok {
+			b = bucket{tokens: float64(l.burst), last: now}
+		}
+		b = l.refill(b, now)
+		if allowed = b.tokens >= float64(n); allowed {
+			b.tokens -= float64(n)
+		}
+		return b
+	}, l.idleAt)
+	if err != nil {
+		return false, err
+	}
+
+	return allowed, nil
+}
+
+// Reset the buckets of the provided keys to full.
+func (l *TokenBucket[K]) Reset(keys ...K) {
+	l.kv.store.Delete(keys...)
+}
+
+// Len returns the number of keys currently tracked.
+func (l *TokenBucket[K]) Len() int {
+	return l.kv.store.Len()
+}
+
+func (l *TokenBucket[K]) refill(b bucket, now time.Time) bucket {
+	if l.interval <= 0 {
+		b.tokens = float64(l.burst)
+	} else if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(float64(l.burst), b.tokens+float64(elapsed)/float64(l.interval))
+	}
+	b.last = now
+
+	return b
+}
+
+// idleAt returns when b will have fully refilled.
+func (l *TokenBucket[K]) idleAt(b bucket) (time.Time, bool) {
+	if l.interval <= 0 {
+		return b.last, true
+	}
+	missing := max(float64(l.burst)-b.tokens, 0)
+
+	return b.last.Add(time.Duration(missing * float64(l.interval))), true
+}
+
+// SlidingWindow is a keyed sliding window rate limiter backed by a [Store].
+//
+// Each key may have at most limit events within any window. The count for the
+// trailing window is approximated by weighting the previous fixed window's
+// count by how much of it still overlaps the sliding window. Keys which have
+// had no events for two windows are reclaimed when the underlying [Store] is at
+// capacity, keeping memory bounded for large key sets such as client IPs.
+type SlidingWindow[K comparable] struct {
+	limit  int
+	window time.Duration
+	kv     keyed[K, counts]
+}
+
+type counts struct {
+	start time.Time
+	prev  int
+	curr  int
+}
+
+// NewSlidingWindow creates a new instance of [SlidingWindow] which tracks at
+// most capacity keys.
+//
+//   - A capacity of zero means there is no limit to the number of keys.
+//   - If the capacity is less than 0, it will be set to 0.
+//   - If limit is less than 0, it will be set to 0.
+//   - A window less than or equal to 0 means every event is allowed.
+func NewSlidingWindow[K comparable](capacity int, limit int, window time.Duration) *SlidingWindow[K] {
+	if limit < 0 {
+		limit = 0
+	}
+
+	return &SlidingWindow[K]{
+		limit:  limit,
+		window: window,
+		kv:     newKeyed[K, counts](capacity),
+	}
+}
+
+// Allow reports whether a single event for key may happen now.
+//
+// An [AtCapacityError] is returned if key is not yet tracked and no room could
+// be reclaimed for it.
+func (l *SlidingWindow[K]) Allow(key K) (bool, error) {
+	return l.AllowN(key, 1)
+}
+
+// AllowN reports whether n events for key may happen now. Events are only
+// counted when they are allowed. An n less than 1 is never allowed.
+//
+// An [AtCapacityError] is returned if key is not yet tracked and no room could
+// be reclaimed for it.
+func (l *SlidingWindow[K]) AllowN(key K, n int) (bool, error) {
+	if n < 1 {
+		return false, nil
+	}
+	if l.window <= 0 {
+		return true, nil
+	}
+
+	var allowed bool
+	err := l.kv.update(key, func(c counts, ok bool, now time.Time) counts {
+		if !ok {
+			c = counts{start: now}
+		}
+		c = l.slide(c, now)
+		weight := 1 - float64(now.Sub(c.start))/float64(l.window)
+		if allowed = float64(c.prev)*weight+float64(c.curr+n) <= float64(l.limit); allowed {
+			c.curr += n
+		}
+		return c
+	}, l.idleAt)
+	if err != nil {
+		return false, err
+	}
+
+	return allowed, nil
+}
+
+// Reset the windows of the provided keys.
+func (l *SlidingWindow[K]) Reset(keys ...K) {
+	l.kv.store.Delete(keys...)
+}
+
+// Len returns the number of keys currently tracked.
+func (l *SlidingWindow[K]) Len() int {
+	return l.kv.store.Len()
+}
+
+func (l *SlidingWindow[K]) slide(c counts, now time.Time) counts {
+	elapsed := now.Sub(c.start)
+	switch {
+	case elapsed >= 2*l.window:
+		c = counts{start: now.Add(-elapsed % l.window)}
+	case elapsed >= l.window:
+		c = counts{start: c.start.Add(l.window), prev: c.curr}
+	}
+
+	return c
+}
+
+// idleAt returns when c will have had no events for two windows.
+func (l *SlidingWindow[K]) idleAt(c counts) (time.Time, bool) {
+	return c.start.Add(2 * l.window), true
+}
+
+// keyed wraps a [Store] to allow atomic read-modify-write operations on its
+// values and reclaiming of idle values when the store is at capacity.
+type keyed[K comparable, V any] struct {
+	mu    *sync.Mutex
+	now   func() time.Time
+	store *Store[K, V]
+
+	// reclaimAt is the earliest time at which any value may be idle, such that
+	// the store is not scanned for idle values again before then.
+	reclaimAt time.Time
+}
+
+// never is a time after which no value becomes idle.
+var never = time.Unix(1<<62, 0)
+
+func newKeyed[K comparable, V any](capacity int) keyed[K, V] {
+	return keyed[K, V]{
+		mu:    &sync.Mutex{},
+		now:   time.Now,
+		store: New[K, V](capacity),
+	}
+}
+
+// update sets the value of key to the result of fn. If key is new and the
+// store is at capacity, all values which are idle according to idleAt are
+// deleted before trying again. idleAt returns when a value becomes idle or
+// false if it never does, and must never return an earlier time for a value
+// after it has been updated.
+//
+// The store is only scanned for idle values once one may have become idle, so
+// that requests for new keys while the store is full of values which are not
+// idle do not each scan it.
+func (k *keyed[K, V]) update(key K, fn func(v V, ok bool, now time.Time) V, idleAt func(v V) (time.Time, bool)) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := k.now()
+	v, ok := k.store.Get(key)
+	v = fn(v, ok, now)
+
+	var atCapacityErr *AtCapacityError
+	err := k.store.Set(key, v)
+	if errors.As(err, &atCapacityErr) && !now.Before(k.reclaimAt) {
+		k.reclaimAt = k.reclaim(now, idleAt)
+		err = k.store.Set(key, v)
+	}
+	if err != nil {
+		return err
+	}
+
+	if at, ok := idleAt(v); ok && at.Before(k.reclaimAt) {
+		k.reclaimAt = at
+	}
+
+	return nil
+}
+
+// reclaim deletes all values which are idle at now, returning the earliest
+// time at which any remaining value becomes idle.
+func (k *keyed[K, V]) reclaim(now time.Time, idleAt func(v V) (time.Time, bool)) time.Time {
+	next := never
+	var keys []K
+	for key, v := range k.store.Items() {
+		at, ok := idleAt(v)
+		switch {
+		case !ok:
+		case !now.Before(at):
+			keys = append(keys, key)
+		case at.Before(next):
+			next = at
+		}
+	}
+	k.store.Delete(keys...)
+
+	return next
+}
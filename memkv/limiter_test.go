@@ -0,0 +1,342 @@
+package memkv_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wafer-bw/go-toolbox/memkv"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newClock() *clock {
+	return &clock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func TestTokenBucket_Allow(t *testing.T) {
+	t.Parallel()
+
+	t.Run("allows up to burst events then denies", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := memkv.NewTokenBucket[string](0, 2, time.Second)
+		limiter.SetNow(newClock().Now)
+
+		for _, want := range []bool{true, true, false} {
+			allowed, err := limiter.Allow("key")
+			require.NoError(t, err)
+			require.Equal(t, want, allowed)
+		}
+	})
+
+	t.Run("refills one token per interval", func(t *testing.T) {
+		t.Parallel()
+
+		clk := newClock()
+		limiter := memkv.NewTokenBucket[string](0, 1, time.Second)
+		limiter.SetNow(clk.Now)
+
+		allowed, err := limiter.Allow("key")
+		require.NoError(t, err)
+		require.True(t, allowed)
+
+		clk.Advance(500 * time.Millisecond)
+		allowed, err = limiter.Allow("key")
+		require.NoError(t, err)
+		require.False(t, allowed)
+
+		clk.Advance(500 * time.Millisecond)
+		allowed, err = limiter.Allow("key")
+		require.NoError(t, err)
+		require.True(t, allowed)
+	})
+
+	t.Run("tracks keys independently", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := memkv.NewTokenBucket[string](0, 1, time.Second)
+		limiter.SetNow(newClock().Now)
+
+		allowed, err := limiter.Allow("key1")
+		require.NoError(t, err)
+		require.True(t, allowed)
+
+		allowed, err = limiter.Allow("key2")
+		require.NoError(t, err)
+		require.True(t, allowed)
+	})
+
+	t.Run("always allows when interval is not positive", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := memkv.NewTokenBucket[string](0, 1, 0)
+		for i := 0; i < 3; i++ {
+			allowed, err := limiter.Allow("key")
+			require.NoError(t, err)
+			require.True(t, allowed)
+		}
+	})
+
+	t.Run("reclaims full buckets when at capacity", func(t *testing.T) {
+		t.Parallel()
+
+		clk := newClock()
+		limiter := memkv.NewTokenBucket[string](1, 1, time.Second)
+		limiter.SetNow(clk.Now)
+
+		_, err := limiter.Allow("key1")
+		require.NoError(t, err)
+
+		clk.Advance(time.Second)
+		allowed, err := limiter.Allow("key2")
+		require.NoError(t, err)
+		require.True(t, allowed)
+		require.Equal(t, 1, limiter.Len())
+	})
+
+	t.Run("returns an error when at capacity and nothing can be reclaimed", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := memkv.NewTokenBucket[string](1, 1, time.Second)
+		limiter.SetNow(newClock().Now)
+
+		_, err := limiter.Allow("key1")
+		require.NoError(t, err)
+
+		allowed, err := limiter.Allow("key2")
+		require.False(t, allowed)
+		require.IsType(t, &memkv.AtCapacityError{}, err)
+	})
+
+	t.Run("reclaims buckets which refilled after a failed reclaim", func(t *testing.T) {
+		t.Parallel()
+
+		clk := newClock()
+		limiter := memkv.NewTokenBucket[string](2, 2, time.Second)
+		limiter.SetNow(clk.Now)
+
+		_, err := limiter.AllowN("key1", 2)
+		require.NoError(t, err)
+		_, err = limiter.Allow("key2")
+		require.NoError(t, err)
+
+		_, err = limiter.Allow("key3")
+		require.IsType(t, &memkv.AtCapacityError{}, err)
+
+		clk.Advance(time.Second)
+		allowed, err := limiter.Allow("key3")
+		require.NoError(t, err)
+		require.True(t, allowed)
+		require.Equal(t, 2, limiter.Len())
+	})
+}
+
+func TestTokenBucket_AllowN(t *testing.T) {
+	t.Parallel()
+
+	t.Run("does not consume tokens when denied", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := memkv.NewTokenBucket[string](0, 3, time.Second)
+		limiter.SetNow(newClock().Now)
+
+		allowed, err := limiter.AllowN("key", 4)
+		require.NoError(t, err)
+		require.False(t, allowed)
+
+		allowed, err = limiter.AllowN("key", 3)
+		require.NoError(t, err)
+		require.True(t, allowed)
+	})
+
+	t.Run("denies n less than 1 without adding tokens", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := memkv.NewTokenBucket[string](0, 1, time.Second)
+		limiter.SetNow(newClock().Now)
+
+		for _, n := range []int{0, -5} {
+			allowed, err := limiter.AllowN("key", n)
+			require.NoError(t, err)
+			require.False(t, allowed)
+		}
+
+		allowed, err := limiter.AllowN("key", 2)
+		require.NoError(t, err)
+		require.False(t, allowed)
+	})
+}
+
+func TestTokenBucket_Reset(t *testing.T) {
+	t.Parallel()
+
+	t.Run("refills the bucket", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := memkv.NewTokenBucket[string](0, 1, time.Second)
+		limiter.SetNow(newClock().Now)
+
+		_, err := limiter.Allow("key")
+		require.NoError(t, err)
+
+		limiter.Reset("key")
+		require.Zero(t, limiter.Len())
+
+		allowed, err := limiter.Allow("key")
+		require.NoError(t, err)
+		require.True(t, allowed)
+	})
+}
+
+func TestSlidingWindow_Allow(t *testing.T) {
+	t.Parallel()
+
+	t.Run("allows up to limit events within a window then denies", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := memkv.NewSlidingWindow[string](0, 2, time.Second)
+		limiter.SetNow(newClock().Now)
+
+		for _, want := range []bool{true, true, false} {
+			allowed, err := limiter.Allow("key")
+			require.NoError(t, err)
+			require.Equal(t, want, allowed)
+		}
+	})
+
+	t.Run("weights the previous window by its overlap", func(t *testing.T) {
+		t.Parallel()
+
+		clk := newClock()
+		limiter := memkv.NewSlidingWindow[string](0, 2, time.Second)
+		limiter.SetNow(clk.Now)
+
+		for i := 0; i < 2; i++ {
+			_, err := limiter.Allow("key")
+			require.NoError(t, err)
+		}
+
+		clk.Advance(1250 * time.Millisecond)
+		allowed, err := limiter.Allow("key")
+		require.NoError(t, err)
+		require.False(t, allowed, "previous window still counts as 1.5 events")
+
+		clk.Advance(250 * time.Millisecond)
+		allowed, err = limiter.Allow("key")
+		require.NoError(t, err)
+		require.True(t, allowed, "previous window now counts as 1 event")
+	})
+
+	t.Run("forgets events older than two windows", func(t *testing.T) {
+		t.Parallel()
+
+		clk := newClock()
+		limiter := memkv.NewSlidingWindow[string](0, 1, time.Second)
+		limiter.SetNow(clk.Now)
+
+		_, err := limiter.Allow("key")
+		require.NoError(t, err)
+
+		clk.Advance(2 * time.Second)
+		allowed, err := limiter.Allow("key")
+		require.NoError(t, err)
+		require.True(t, allowed)
+	})
+
+	t.Run("always allows when window is not positive", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := memkv.NewSlidingWindow[string](0, 0, 0)
+		allowed, err := limiter.Allow("key")
+		require.NoError(t, err)
+		require.True(t, allowed)
+	})
+
+	t.Run("reclaims idle keys when at capacity", func(t *testing.T) {
+		t.Parallel()
+
+		clk := newClock()
+		limiter := memkv.NewSlidingWindow[string](1, 1, time.Second)
+		limiter.SetNow(clk.Now)
+
+		_, err := limiter.Allow("key1")
+		require.NoError(t, err)
+
+		clk.Advance(2 * time.Second)
+		allowed, err := limiter.Allow("key2")
+		require.NoError(t, err)
+		require.True(t, allowed)
+		require.Equal(t, 1, limiter.Len())
+	})
+
+	t.Run("returns an error when at capacity and nothing can be reclaimed", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := memkv.NewSlidingWindow[string](1, 1, time.Second)
+		limiter.SetNow(newClock().Now)
+
+		_, err := limiter.Allow("key1")
+		require.NoError(t, err)
+
+		allowed, err := limiter.Allow("key2")
+		require.False(t, allowed)
+		require.IsType(t, &memkv.AtCapacityError{}, err)
+	})
+}
+
+func TestSlidingWindow_AllowN(t *testing.T) {
+	t.Parallel()
+
+	t.Run("denies n less than 1 without uncounting events", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := memkv.NewSlidingWindow[string](0, 1, time.Second)
+		limiter.SetNow(newClock().Now)
+
+		allowed, err := limiter.Allow("key")
+		require.NoError(t, err)
+		require.True(t, allowed)
+
+		for _, n := range []int{0, -5} {
+			allowed, err := limiter.AllowN("key", n)
+			require.NoError(t, err)
+			require.False(t, allowed)
+		}
+
+		allowed, err = limiter.Allow("key")
+		require.NoError(t, err)
+		require.False(t, allowed)
+	})
+}
+
+func TestSlidingWindow_Reset(t *testing.T) {
+	t.Parallel()
+
+	t.Run("clears the window", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := memkv.NewSlidingWindow[string](0, 1, time.Second)
+		limiter.SetNow(newClock().Now)
+
+		_, err := limiter.Allow("key")
+		require.NoError(t, err)
+
+		limiter.Reset("key")
+		require.Zero(t, limiter.Len())
+
+		allowed, err := limiter.Allow("key")
+		require.NoError(t, err)
+		require.True(t, allowed)
+	})
+}
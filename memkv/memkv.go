@@ -1,4 +1,5 @@
-// Package memkv provides a generic in-memory key-value store.
+// Package memkv provides a generic in-memory key-value store along with keyed
+// rate limiters & counters built on top of it.
 package memkv
 
 import (
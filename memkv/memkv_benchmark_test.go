@@ -3,6 +3,7 @@ package memkv_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/wafer-bw/go-toolbox/memkv"
 )
@@ -151,3 +152,50 @@ func BenchmarkStore_Values(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkTokenBucket_Allow(b *testing.B) {
+	for _, size := range sizes {
+		limiter := memkv.NewTokenBucket[int](size, 10, time.Second)
+
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := limiter.Allow(i % size); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkTokenBucket_Allow_atCapacity(b *testing.B) {
+	for _, size := range sizes {
+		limiter := memkv.NewTokenBucket[int](size, 10, time.Hour)
+		for i := 0; i < size; i++ {
+			if _, err := limiter.AllowN(i, 10); err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := limiter.Allow(size + i); err == nil {
+					b.Fatal("expected an error")
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCounter_Incr(b *testing.B) {
+	for _, size := range sizes {
+		counter := memkv.NewCounter[int](size, time.Minute)
+
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := counter.Incr(i % size); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package memkv_test
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/wafer-bw/go-toolbox/memkv"
 )
@@ -194,3 +195,54 @@ func ExampleStore_Values() {
 	// Output:
 	// [val1 val2]
 }
+
+func ExampleTokenBucket_Allow() {
+	limiter := memkv.NewTokenBucket[string](1000, 2, time.Second)
+
+	for i := 0; i < 3; i++ {
+		allowed, err := limiter.Allow("127.0.0.1")
+		if err != nil {
+			return
+		}
+		fmt.Println(allowed)
+	}
+
+	// Output:
+	// true
+	// true
+	// false
+}
+
+func ExampleSlidingWindow_Allow() {
+	limiter := memkv.NewSlidingWindow[string](1000, 2, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		allowed, err := limiter.Allow("127.0.0.1")
+		if err != nil {
+			return
+		}
+		fmt.Println(allowed)
+	}
+
+	// Output:
+	// true
+	// true
+	// false
+}
+
+func ExampleCounter_Incr() {
+	counter := memkv.NewCounter[string](1000, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		n, err := counter.Incr("127.0.0.1")
+		if err != nil {
+			return
+		}
+		fmt.Println(n)
+	}
+
+	// Output:
+	// 1
+	// 2
+	// 3
+}
@@ -1,6 +1,10 @@
 package memkv
 
-import "github.com/wafer-bw/go-toolbox/memkv/internal/underlying"
+import (
+	"time"
+
+	"github.com/wafer-bw/go-toolbox/memkv/internal/underlying"
+)
 
 // export for testing.
 func (s *Store[K, V]) Capacity() int {
@@ -12,3 +16,18 @@ func (s *Store[K, V]) Data() (*underlying.Data[K, V], func()) {
 	s.mu.Lock()
 	return s.data, s.mu.Unlock
 }
+
+// export for testing.
+func (l *TokenBucket[K]) SetNow(now func() time.Time) {
+	l.kv.now = now
+}
+
+// export for testing.
+func (l *SlidingWindow[K]) SetNow(now func() time.Time) {
+	l.kv.now = now
+}
+
+// export for testing.
+func (c *Counter[K]) SetNow(now func() time.Time) {
+	c.kv.now = now
+}
@@ -0,0 +1,182 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Cached is a [Prober] which executes another Prober on a background interval
+// and serves the result of its most recent execution, such that probing it is
+// instant regardless of how expensive the check is.
+//
+// The background executions happen between calls to [Cached.Start] and
+// [Cached.Stop], which satisfy the Runner interface of the
+// github.com/wafer-bw/go-toolbox/graceful package. A Cached must not be copied
+// after first use.
+type Cached struct {
+	// Prober is the [Prober] to execute.
+	Prober Prober
+
+	// Interval is how often Prober is executed.
+	Interval time.Duration
+
+	// Timeout is how long each execution of Prober may take. A non-positive
+	// Timeout uses Interval.
+	Timeout time.Duration
+
+	// StaleAfter is the age after which a result is treated as a failure with
+	// a [StaleError]. A non-positive StaleAfter means results never become
+	// stale.
+	StaleAfter time.Duration
+
+	mu       sync.Mutex
+	err      error
+	at       time.Time
+	stopping bool
+	stopCh   chan struct{}
+}
+
+// Probe returns the result of the most recent execution of Prober. It returns
+// a [PendingError] if Prober has not been executed yet and a [StaleError] if
+// the result is older than StaleAfter.
+func (c *Cached) Probe(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.at.IsZero() {
+		return &PendingError{}
+	}
+	if age := time.Since(c.at); c.StaleAfter > 0 && age > c.StaleAfter {
+		return &StaleError{Age: age, StaleAfter: c.StaleAfter, Err: c.err}
+	}
+
+	return c.err
+}
+
+// Age returns how long ago Prober was last executed and false if it has not
+// been executed yet.
+func (c *Cached) Age() (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.at.IsZero() {
+		return 0, false
+	}
+
+	return time.Since(c.at), true
+}
+
+// Start executing Prober immediately then every Interval until
+// [Cached.Stop] is called or the passed context is canceled.
+func (c *Cached) Start(ctx context.Context) error {
+	if c.Interval <= 0 {
+		return fmt.Errorf("non-positive interval %s", c.Interval)
+	}
+
+	c.mu.Lock()
+	if c.stopping {
+		c.mu.Unlock()
+		return nil
+	}
+	if c.stopCh == nil {
+		c.stopCh = make(chan struct{})
+	}
+	stopCh := c.stopCh
+	c.mu.Unlock()
+
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	for {
+		c.refresh(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-stopCh:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Stop executing Prober, making [Cached.Start] return.
+func (c *Cached) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopping {
+		return nil
+	}
+	c.stopping = true
+	if c.stopCh == nil {
+		c.stopCh = make(chan struct{})
+	}
+	close(c.stopCh)
+
+	return nil
+}
+
+// refresh executes Prober, recording its result.
+func (c *Cached) refresh(ctx context.Context) {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = c.Interval
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := c.Prober.Probe(ctx)
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return // stopping, keep the previous result.
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err, c.at = err, time.Now()
+}
+
+// Ages returns the age of the result of each [Prober] in the group which caches
+// its results, such as [Cached], keyed by probe name. Probes which have not
+// been executed yet are omitted.
+func (g Group) Ages() map[string]time.Duration {
+	ages := make(map[string]time.Duration, len(g))
+	for key, probe := range g {
+		ager, ok := probe.(interface{ Age() (time.Duration, bool) })
+		if !ok {
+			continue
+		}
+		if age, ok := ager.Age(); ok {
+			ages[key] = age
+		}
+	}
+
+	return ages
+}
+
+// PendingError occurs when a [Cached] is probed before its Prober has been
+// executed.
+type PendingError struct{}
+
+func (e *PendingError) Error() string {
+	return "not yet probed"
+}
+
+// StaleError occurs when a [Cached] is probed and the result of its most
+// recent execution is older than its StaleAfter. Err is that result.
+type StaleError struct {
+	Age        time.Duration
+	StaleAfter time.Duration
+	Err        error
+}
+
+func (e *StaleError) Error() string {
+	return fmt.Sprintf("result is %s old, stale after %s", e.Age.Round(time.Millisecond), e.StaleAfter)
+}
+
+func (e *StaleError) Unwrap() error {
+	return e.Err
+}
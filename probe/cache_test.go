@@ -0,0 +1,150 @@
+package probe_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wafer-bw/go-toolbox/probe"
+)
+
+func TestCached(t *testing.T) {
+	t.Parallel()
+
+	t.Run("serves the most recent result & refreshes it in the background", func(t *testing.T) {
+		t.Parallel()
+
+		probeErr := errors.New("failed")
+		calls := &atomic.Int32{}
+		c := &probe.Cached{
+			Prober: probe.ProberFunc(func(ctx context.Context) error {
+				if calls.Add(1) > 1 {
+					return probeErr
+				}
+				return nil
+			}),
+			Interval: 10 * time.Millisecond,
+		}
+		require.ErrorAs(t, c.Probe(context.Background()), new(*probe.PendingError))
+		_, ok := c.Age()
+		require.False(t, ok)
+
+		errCh := make(chan error, 1)
+		go func() { errCh <- c.Start(context.Background()) }()
+
+		require.Eventually(t, func() bool {
+			return calls.Load() >= 1 && c.Probe(context.Background()) == nil
+		}, time.Second, time.Millisecond)
+		require.Eventually(t, func() bool {
+			return errors.Is(c.Probe(context.Background()), probeErr)
+		}, time.Second, time.Millisecond)
+		age, ok := c.Age()
+		require.True(t, ok)
+		require.Less(t, age, time.Second)
+
+		require.NoError(t, c.Stop(context.Background()))
+		require.NoError(t, <-errCh)
+	})
+
+	t.Run("fails results older than the stale threshold", func(t *testing.T) {
+		t.Parallel()
+
+		unblockCh := make(chan struct{})
+		defer close(unblockCh)
+		calls := &atomic.Int32{}
+		c := &probe.Cached{
+			Prober: probe.ProberFunc(func(ctx context.Context) error {
+				if calls.Add(1) > 1 {
+					<-unblockCh
+				}
+				return nil
+			}),
+			Interval:   5 * time.Millisecond,
+			Timeout:    time.Minute,
+			StaleAfter: 20 * time.Millisecond,
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() { _ = c.Start(ctx) }()
+
+		require.Eventually(t, func() bool {
+			return calls.Load() == 2
+		}, time.Second, time.Millisecond)
+		require.Eventually(t, func() bool {
+			staleErr := &probe.StaleError{}
+			if !errors.As(c.Probe(context.Background()), &staleErr) {
+				return false
+			}
+			return staleErr.Age > 20*time.Millisecond && staleErr.StaleAfter == 20*time.Millisecond
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("returns an error for a non-positive interval", func(t *testing.T) {
+		t.Parallel()
+
+		c := &probe.Cached{Prober: probe.ProberFunc(func(ctx context.Context) error { return nil })}
+		require.Error(t, c.Start(context.Background()))
+	})
+
+	t.Run("returns immediately when started after being stopped", func(t *testing.T) {
+		t.Parallel()
+
+		c := &probe.Cached{
+			Prober:   probe.ProberFunc(func(ctx context.Context) error { return nil }),
+			Interval: time.Minute,
+		}
+		require.NoError(t, c.Stop(context.Background()))
+		require.NoError(t, c.Start(context.Background()))
+	})
+
+	t.Run("is served instantly within a group", func(t *testing.T) {
+		t.Parallel()
+
+		unblockCh := make(chan struct{})
+		defer close(unblockCh)
+		calls := &atomic.Int32{}
+		c := &probe.Cached{
+			Prober: probe.ProberFunc(func(ctx context.Context) error {
+				if calls.Add(1) > 1 {
+					<-unblockCh
+				}
+				return nil
+			}),
+			Interval: time.Millisecond,
+			Timeout:  time.Minute,
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() { _ = c.Start(ctx) }()
+		require.Eventually(t, func() bool { return calls.Load() == 2 }, time.Second, time.Millisecond)
+
+		g := probe.Group{"db": c, "other": probe.ProberFunc(func(ctx context.Context) error { return nil })}
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		ages := g.Ages()
+		require.Len(t, ages, 1)
+		require.Contains(t, ages, "db")
+	})
+}
+
+func TestStaleError_Error(t *testing.T) {
+	t.Parallel()
+
+	err := &probe.StaleError{Age: 2 * time.Second, StaleAfter: time.Second}
+	require.Equal(t, "result is 2s old, stale after 1s", err.Error())
+}
+
+func TestPendingError_Error(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "not yet probed", (&probe.PendingError{}).Error())
+}
@@ -5,7 +5,10 @@ package probe
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 )
 
 // OkState is the string representation of a successful probe used in the
@@ -35,22 +38,132 @@ func (f ProberFunc) Probe(ctx context.Context) error {
 // Group of Probers to probe.
 type Group map[string]Prober
 
-// ProbeAll executes Probe() on each [Prober] returning a map of results and a
-// bool representing whether or not all probes were successful.
-func (g Group) ProbeAll(ctx context.Context) (map[string]error, bool) {
+// Option configures [Group.ProbeAll] & [Group.Handler].
+type Option func(*config)
+
+type config struct {
+	workers      int
+	timeout      time.Duration
+	probeTimeout time.Duration
+}
+
+// WithWorkers sets the maximum number of probes executed at once. By default
+// all probes are executed at once.
+func WithWorkers(n int) Option {
+	return func(cfg *config) {
+		cfg.workers = n
+	}
+}
+
+// WithTimeout sets the overall timeout for executing all probes. Probes which
+// have not finished within d, including those still waiting for a worker (see
+// [WithWorkers]), fail with a [TimeoutError].
+func WithTimeout(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.timeout = d
+	}
+}
+
+// WithProbeTimeout sets the timeout for executing each probe. A probe which
+// has not finished within d of it starting fails with a [TimeoutError].
+func WithProbeTimeout(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.probeTimeout = d
+	}
+}
+
+// ProbeAll executes Probe() on each [Prober] concurrently returning a map of
+// results and a bool representing whether or not all probes were successful.
+//
+// The context passed to each Probe() is canceled once it times out (see
+// [WithTimeout] & [WithProbeTimeout]) or the passed context is canceled. A
+// probe which does not return once its context is canceled is abandoned and
+// its result is the cause of the cancellation.
+func (g Group) ProbeAll(ctx context.Context, opts ...Option) (map[string]error, bool) {
+	cfg := &config{}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(cfg)
+	}
+
+	if cfg.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, cfg.timeout, &TimeoutError{Timeout: cfg.timeout})
+		defer cancel()
+	}
+
+	workers := cfg.workers
+	if workers <= 0 || workers > len(g) {
+		workers = len(g)
+	}
+	sem := make(chan struct{}, workers)
+
+	type result struct {
+		key string
+		err error
+	}
+	resultCh := make(chan result, len(g))
+	for probeKey, probe := range g {
+		go func() {
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+				resultCh <- result{key: probeKey, err: probeOne(ctx, probe, cfg.probeTimeout)}
+			case <-ctx.Done():
+				resultCh <- result{key: probeKey, err: context.Cause(ctx)}
+			}
+		}()
+	}
+
 	ok := true
 	results := make(map[string]error, len(g))
-	for probeKey, probe := range g {
-		err := probe.Probe(ctx)
-		if err != nil {
+	for range len(g) {
+		r := <-resultCh
+		if r.err != nil {
 			ok = false
 		}
-		results[probeKey] = err
+		results[r.key] = r.err
 	}
 
 	return results, ok
 }
 
+// probeOne executes Probe() on probe, giving it at most timeout to return
+// unless timeout is less than or equal to 0.
+func probeOne(ctx context.Context, probe Prober, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, &TimeoutError{Timeout: timeout})
+		defer cancel()
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- probe.Probe(ctx) }()
+
+	select {
+	case err := <-errCh:
+		var timeoutErr *TimeoutError
+		if err != nil && ctx.Err() != nil && errors.As(context.Cause(ctx), &timeoutErr) {
+			return timeoutErr
+		}
+		return err
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// TimeoutError occurs when a probe does not finish within the timeout set via
+// [WithTimeout] or [WithProbeTimeout].
+type TimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("probe timed out after %s", e.Timeout)
+}
+
 // ServeHTTP probes the state of all [Prober] in the group.
 //
 // Response status codes:
@@ -63,10 +176,22 @@ func (g Group) ProbeAll(ctx context.Context) (map[string]error, bool) {
 // means the probe was successful, otherwise the value is the string
 // representation of the error returned by the probe.
 func (g Group) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.serveHTTP(w, r)
+}
+
+// Handler returns an [http.Handler] which behaves like [Group.ServeHTTP] but
+// probes the group using opts.
+func (g Group) Handler(opts ...Option) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.serveHTTP(w, r, opts...)
+	})
+}
+
+func (g Group) serveHTTP(w http.ResponseWriter, r *http.Request, opts ...Option) {
 	ctx := r.Context()
 	status := http.StatusOK
 
-	results, ok := g.ProbeAll(ctx)
+	results, ok := g.ProbeAll(ctx, opts...)
 	if !ok {
 		status = http.StatusServiceUnavailable
 	}
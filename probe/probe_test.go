@@ -7,7 +7,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/wafer-bw/go-toolbox/probe"
@@ -43,6 +45,117 @@ func TestGroup_ProbeAll(t *testing.T) {
 		require.False(t, ok)
 		require.Equal(t, map[string]error{"p1": err, "p2": nil, "p3": err}, results)
 	})
+
+	t.Run("executes probes concurrently", func(t *testing.T) {
+		t.Parallel()
+
+		p1Ch, p2Ch := make(chan struct{}), make(chan struct{})
+		probes := probe.Group{
+			"p1": probe.ProberFunc(func(ctx context.Context) error {
+				close(p1Ch)
+				<-p2Ch
+				return nil
+			}),
+			"p2": probe.ProberFunc(func(ctx context.Context) error {
+				close(p2Ch)
+				<-p1Ch
+				return nil
+			}),
+		}
+
+		results, ok := probes.ProbeAll(context.Background(), probe.WithTimeout(time.Second))
+		require.True(t, ok)
+		require.Equal(t, map[string]error{"p1": nil, "p2": nil}, results)
+	})
+
+	t.Run("executes at most the configured number of probes at once", func(t *testing.T) {
+		t.Parallel()
+
+		running, most := &atomic.Int32{}, &atomic.Int32{}
+		p := probe.ProberFunc(func(ctx context.Context) error {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := most.Load()
+				if n <= m || most.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			return nil
+		})
+		probes := probe.Group{"p1": p, "p2": p, "p3": p, "p4": p, "p5": p}
+
+		_, ok := probes.ProbeAll(context.Background(), probe.WithWorkers(2))
+		require.True(t, ok)
+		require.Equal(t, int32(2), most.Load())
+	})
+
+	t.Run("fails probes which exceed the probe timeout with a timeout error", func(t *testing.T) {
+		t.Parallel()
+
+		unblockCh := make(chan struct{})
+		defer close(unblockCh)
+		probes := probe.Group{
+			"p1": probe.ProberFunc(func(ctx context.Context) error { return nil }),
+			"p2": probe.ProberFunc(func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}),
+			"p3": probe.ProberFunc(func(ctx context.Context) error {
+				<-unblockCh
+				return nil
+			}),
+		}
+
+		results, ok := probes.ProbeAll(context.Background(), probe.WithProbeTimeout(10*time.Millisecond))
+		require.False(t, ok)
+		require.NoError(t, results["p1"])
+		for _, key := range []string{"p2", "p3"} {
+			timeoutErr := &probe.TimeoutError{}
+			require.ErrorAs(t, results[key], &timeoutErr)
+			require.Equal(t, 10*time.Millisecond, timeoutErr.Timeout)
+		}
+	})
+
+	t.Run("fails probes which have not finished within the overall timeout", func(t *testing.T) {
+		t.Parallel()
+
+		unblockCh := make(chan struct{})
+		defer close(unblockCh)
+		blocking := probe.ProberFunc(func(ctx context.Context) error {
+			<-unblockCh
+			return nil
+		})
+		probes := probe.Group{"p1": blocking, "p2": blocking}
+
+		results, ok := probes.ProbeAll(context.Background(), probe.WithWorkers(1), probe.WithTimeout(10*time.Millisecond))
+		require.False(t, ok)
+		for _, key := range []string{"p1", "p2"} {
+			require.ErrorAs(t, results[key], new(*probe.TimeoutError))
+		}
+	})
+
+	t.Run("fails probes with the cause of the passed context being canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		probes := probe.Group{
+			"p1": probe.ProberFunc(func(ctx context.Context) error { return ctx.Err() }),
+		}
+
+		results, ok := probes.ProbeAll(ctx)
+		require.False(t, ok)
+		require.ErrorIs(t, results["p1"], context.Canceled)
+	})
+}
+
+func TestTimeoutError_Error(t *testing.T) {
+	t.Parallel()
+
+	err := &probe.TimeoutError{Timeout: time.Second}
+	require.Equal(t, "probe timed out after 1s", err.Error())
 }
 
 func TestGroup_ServeHTTP(t *testing.T) {
@@ -86,3 +199,27 @@ func TestGroup_ServeHTTP(t *testing.T) {
 		require.Equal(t, expectedResponse, strings.TrimSpace(body))
 	})
 }
+
+func TestGroup_Handler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("probes using the provided options", func(t *testing.T) {
+		t.Parallel()
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+		h := probe.Group{
+			"p1": probe.ProberFunc(func(ctx context.Context) error { return nil }),
+			"p2": probe.ProberFunc(func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}),
+		}.Handler(probe.WithProbeTimeout(time.Millisecond))
+		expectedResponse := fmt.Sprintf(`{"p1":"%s","p2":"probe timed out after 1ms"}`, probe.OkState)
+
+		h.ServeHTTP(w, r)
+		body := w.Body.String()
+		require.Equal(t, http.StatusServiceUnavailable, w.Code, body)
+		require.Equal(t, expectedResponse, strings.TrimSpace(body))
+	})
+}